@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +17,11 @@ type Config struct {
 	StoredPalsFile string
 	PassiveSkillsFile string
 	PassiveSkillCombosFile string
+	StoreBackend   string
+	SQLiteFile     string
+	BackupRetention int
+	DataReloadInterval int
+	MaxPalLevel    int
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -28,13 +34,18 @@ func LoadConfig() *Config {
 		Port:           getEnv("PORT", "8080"),
 		GinMode:        getEnv("GIN_MODE", "release"),
 		AllowedOrigins: getEnvSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:3001"}),
-		AllowedMethods: getEnvSlice("ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		AllowedHeaders: getEnvSlice("ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization"}),
+		AllowedMethods: getEnvSlice("ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		AllowedHeaders: getEnvSlice("ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Profile"}),
 		DataDir:        getEnv("DATA_DIR", "./data"),
 		PalsFile:       getEnv("PALS_FILE", "pals.json"),
 		StoredPalsFile: getEnv("STORED_PALS_FILE", "stored_pals.json"),
 		PassiveSkillsFile: getEnv("PASSIVE_SKILLS_FILE", "passive_skills.json"),
 		PassiveSkillCombosFile: getEnv("PASSIVE_SKILL_COMBOS_FILE", "passive_skill_combos.json"),
+		StoreBackend:   getEnv("STORE_BACKEND", "json"),
+		SQLiteFile:     getEnv("SQLITE_FILE", "palworld.db"),
+		BackupRetention: getEnvInt("BACKUP_RETENTION", 10),
+		DataReloadInterval: getEnvInt("DATA_RELOAD_INTERVAL", 2),
+		MaxPalLevel:    getEnvInt("MAX_PAL_LEVEL", 65),
 	}
 }
 
@@ -46,6 +57,14 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt gets an environment variable as a number with a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // getEnvSlice gets an environment variable as a slice with a default value
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
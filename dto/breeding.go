@@ -0,0 +1,145 @@
+package dto
+
+type BreedingStep struct {
+	ParentA string `json:"parent_a"`
+	ParentB string `json:"parent_b"`
+	Child   string `json:"child"`
+}
+
+type BreedingParent struct {
+	Species       string   `json:"species"`
+	Gender        string   `json:"gender"`
+	PalId         string   `json:"pal_id,omitempty"`
+	Bred          bool     `json:"bred"`
+	PassiveSkills []string `json:"passive_skills,omitempty"`
+}
+
+type PlannedBreedingStep struct {
+	ParentA BreedingParent `json:"parent_a"`
+	ParentB BreedingParent `json:"parent_b"`
+	Child   string         `json:"child"`
+
+	ExpectedEggs      float64 `json:"expected_eggs"`
+	Cakes             float64 `json:"cakes"`
+	IncubationMinutes float64 `json:"incubation_minutes"`
+}
+
+type MissingPal struct {
+	Species string `json:"species"`
+	Gender  string `json:"gender"`
+}
+
+type BreedingPlan struct {
+	Target      string                `json:"target"`
+	Steps       []PlannedBreedingStep `json:"steps"`
+	MissingPals []MissingPal          `json:"missing_pals"`
+
+	TotalExpectedEggs      float64 `json:"total_expected_eggs"`
+	TotalCakes             float64 `json:"total_cakes"`
+	TotalIncubationMinutes float64 `json:"total_incubation_minutes"`
+}
+
+type ParentPair struct {
+	ParentA string `json:"parent_a"`
+	ParentB string `json:"parent_b"`
+	Owned   bool   `json:"owned"`
+}
+
+type BreedingChild struct {
+	ParentA     string        `json:"parent_a"`
+	ParentB     string        `json:"parent_b"`
+	Name        string        `json:"name"`
+	ImageUrl    string        `json:"image_url"`
+	Source      string        `json:"source"`
+	Suitability []Suitability `json:"suitability"`
+}
+
+type SimulateBreedingRequest struct {
+	ParentA []string `json:"parent_a"`
+	ParentB []string `json:"parent_b"`
+	Desired []string `json:"desired"`
+	Trials  int      `json:"trials"`
+	Seed    *int64   `json:"seed"`
+}
+
+type PassiveSimulation struct {
+	Desired      []string `json:"desired"`
+	Trials       int      `json:"trials"`
+	Seed         int64    `json:"seed"`
+	Matches      int      `json:"matches"`
+	Probability  float64  `json:"probability"`
+	ExpectedEggs float64  `json:"expected_eggs"`
+}
+
+type GoalPlanRequest struct {
+	Target        string   `json:"target"`
+	PassiveSkills []string `json:"passive_skills"`
+	Combo         string   `json:"combo"`
+}
+
+type GoalPlan struct {
+	Target        string       `json:"target"`
+	PassiveSkills []string     `json:"passive_skills"`
+	Plans         []RankedPlan `json:"plans"`
+}
+
+type RankedPlan struct {
+	Rank                   int            `json:"rank"`
+	TotalExpectedEggs      float64        `json:"total_expected_eggs"`
+	TotalCakes             float64        `json:"total_cakes"`
+	TotalIncubationMinutes float64        `json:"total_incubation_minutes"`
+	Steps                  []GoalPlanStep `json:"steps"`
+}
+
+type GoalPlanStep struct {
+	ParentA       BreedingParent `json:"parent_a"`
+	ParentB       BreedingParent `json:"parent_b"`
+	Child         string         `json:"child"`
+	PassiveSkills []string       `json:"passive_skills"`
+	Probability   float64        `json:"probability"`
+	ExpectedEggs  float64        `json:"expected_eggs"`
+
+	Cakes             float64 `json:"cakes"`
+	IncubationMinutes float64 `json:"incubation_minutes"`
+}
+
+type BreedingValidationReport struct {
+	AsymmetricPairs  []BreedingIssue    `json:"asymmetric_pairs"`
+	ConflictingPairs []BreedingConflict `json:"conflicting_pairs"`
+	UnresolvedNames  []UnresolvedName   `json:"unresolved_names"`
+}
+
+type BreedingIssue struct {
+	Pal    string `json:"pal"`
+	Parent string `json:"parent"`
+	Child  string `json:"child"`
+}
+
+type BreedingConflict struct {
+	ParentA  string   `json:"parent_a"`
+	ParentB  string   `json:"parent_b"`
+	Children []string `json:"children"`
+}
+
+type UnresolvedName struct {
+	Pal  string `json:"pal"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type StoredPalRef struct {
+	Species string `json:"species"`
+	PalId   string `json:"pal_id"`
+}
+
+type StoredPalPair struct {
+	Male   StoredPalRef `json:"male"`
+	Female StoredPalRef `json:"female"`
+}
+
+type AvailableChild struct {
+	Child    string          `json:"child"`
+	ImageUrl string          `json:"image_url"`
+	Owned    bool            `json:"owned"`
+	Pairs    []StoredPalPair `json:"pairs"`
+}
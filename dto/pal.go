@@ -1,18 +1,52 @@
 package dto
 
+import "time"
+
 type AddPalRequest struct {
-	Name          string   `json:"name"`
-	Gender        string   `json:"gender"`
-	PassiveSkills []string `json:"passive_skills"`
+	Name             string   `json:"name"`
+	Gender           string   `json:"gender"`
+	Nickname         string   `json:"nickname"`
+	Level            int      `json:"level"`
+	Talents          Talents  `json:"talents"`
+	CondensationRank int      `json:"condensation_rank"`
+	Container        string   `json:"container"`
+	PassiveSkills    []string `json:"passive_skills"`
+	ActiveSkills     []string `json:"active_skills"`
+}
+
+type Talents struct {
+	HP      int `json:"hp"`
+	Attack  int `json:"attack"`
+	Defense int `json:"defense"`
+}
+
+// UpdatePalRequest changes a stored pal. PATCH leaves missing fields as they are,
+// PUT requires name and gender and resets every other missing field.
+type UpdatePalRequest struct {
+	Name             *string  `json:"name"`
+	Gender           *string  `json:"gender"`
+	Nickname         *string  `json:"nickname"`
+	Level            *int     `json:"level"`
+	Talents          *Talents `json:"talents"`
+	CondensationRank *int     `json:"condensation_rank"`
+	Container        *string  `json:"container"`
+	PassiveSkills    []string `json:"passive_skills"`
+	ActiveSkills     []string `json:"active_skills"`
 }
 
 type Pal struct {
-	Id       int    `json:"id"`
+	Id       string `json:"id"`
 	Name     string `json:"name"`
 	ImageUrl string `json:"image_url"`
 	Gender   string `json:"gender"`
 
-	PassiveSkills []PassiveSkill `json:"passive_skills"`
+	Nickname         string         `json:"nickname"`
+	Level            int            `json:"level"`
+	Talents          Talents        `json:"talents"`
+	CondensationRank int            `json:"condensation_rank"`
+	Container        string         `json:"container"`
+	PassiveSkills    []PassiveSkill `json:"passive_skills"`
+	ActiveSkills     []string       `json:"active_skills"`
 }
 
 type PassiveSkill struct {
@@ -24,6 +58,80 @@ type PalSpecies struct {
 }
 
 type RemovePalRequest struct {
+	Id string `json:"id"`
+}
+
+type Suitability struct {
+	Work  string `json:"work"`
+	Level int    `json:"level"`
+}
+
+// StorePalRecord is one stored pal in a store export or import
+type StorePalRecord struct {
+	Id string `json:"id"`
+	AddPalRequest
+}
+
+type ImportResult struct {
+	Mode    string `json:"mode"`
+	Added   int    `json:"added"`
+	Updated int    `json:"updated"`
+	Removed int    `json:"removed"`
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type SaveImportResult struct {
+	ImportResult
+	Found           int              `json:"found"`
+	Skipped         []SkippedSavePal `json:"skipped"`
+	UnknownPassives []string         `json:"unknown_passives"`
+}
+
+// SkippedSavePal is a pal in a save file that couldn't be imported
+type SkippedSavePal struct {
+	Id          string `json:"id"`
+	CharacterId string `json:"character_id"`
+	Reason      string `json:"reason"`
+}
+
+type StoreEvent struct {
+	Id      int         `json:"id"`
+	Time    time.Time   `json:"time"`
+	Action  string      `json:"action"`
+	Source  string      `json:"source"`
+	Target  int         `json:"target,omitempty"`
+	Undone  bool        `json:"undone"`
+	Changes []PalChange `json:"changes"`
+}
+
+type PalChange struct {
+	PalId  string          `json:"pal_id"`
+	Before *StorePalRecord `json:"before"`
+	After  *StorePalRecord `json:"after"`
+}
+
+// Backup is a snapshot of the data files with the number of entries in each
+type Backup struct {
+	Id                 string    `json:"id"`
+	Time               time.Time `json:"time"`
+	Reason             string    `json:"reason"`
+	Pals               int       `json:"pals"`
+	PassiveSkills      int       `json:"passive_skills"`
+	PassiveSkillCombos int       `json:"passive_skill_combos"`
+	StoredPals         int       `json:"stored_pals"`
+	Profiles           int       `json:"profiles"`
+}
+
+// Profile is a separate pal store, such as one per world or server
+type Profile struct {
+	Name       string `json:"name"`
+	StoredPals int    `json:"stored_pals"`
+}
+
+type ProfileRequest struct {
 	Name string `json:"name"`
-	Id   int    `json:"id"`
 }
@@ -2,15 +2,21 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"palworld_tools/config"
 	"palworld_tools/dto"
 	"palworld_tools/models"
+	"palworld_tools/services/breeding"
 	"palworld_tools/services/datamanage"
 	"palworld_tools/services/options"
+	"palworld_tools/services/savefile"
 	"palworld_tools/services/scrapper"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,10 +24,47 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	profileHeader = "X-Profile"
+	profileKey    = "profile"
+)
+
 func main() {
 	// Load configuration from environment variables
 	cfg := config.LoadConfig()
 
+	// Open the configured storage backend in the configured data directory
+	dataService, err := datamanage.NewService(cfg)
+	if err != nil {
+		fmt.Println("Error opening store:", err)
+		os.Exit(1)
+	}
+	breedingService := breeding.NewService(dataService)
+
+	// Run a one-off command instead of the server when one is given
+	if len(os.Args) > 1 {
+		err := runCommand(dataService, breedingService, os.Args[1], os.Args[2:])
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Precompute the breeding matrix so breeding queries don't re-read the paldex
+	if err := breedingService.RebuildIndex(); err != nil {
+		fmt.Println("Error building breeding index:", err)
+	}
+
+	// Pick up data files changed on disk, and rebuild the breeding index from them
+	if cfg.DataReloadInterval > 0 {
+		dataService.WatchData(time.Duration(cfg.DataReloadInterval)*time.Second, func() {
+			if err := breedingService.RebuildIndex(); err != nil {
+				fmt.Println("Error building breeding index:", err)
+			}
+		})
+	}
+
 	// Set Gin mode based on configuration
 	gin.SetMode(cfg.GinMode)
 
@@ -35,7 +78,7 @@ func main() {
 	r.Use(cors.New(corsConfig))
 
 	r.GET("/update-data", func(ctx *gin.Context) {
-		err := updateData()
+		err := updateData(dataService, breedingService)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -44,6 +87,186 @@ func main() {
 
 	})
 
+	// The pal store and breeding routes use the profile named by the X-Profile header,
+	// or the default profile. Under /profiles/:profile the path names the profile.
+	registerStoreRoutes(r.Group("", selectProfile(dataService)), dataService, breedingService)
+	registerStoreRoutes(r.Group("/profiles/:profile", selectProfile(dataService)), dataService, breedingService)
+
+	profileGroup := r.Group("/profiles")
+	{
+		profileGroup.GET("", func(ctx *gin.Context) {
+			profiles, err := dataService.ListProfiles()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": profiles})
+		})
+
+		profileGroup.POST("", func(ctx *gin.Context) {
+			var request dto.ProfileRequest
+
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			err := dataService.CreateProfile(request.Name)
+			if errors.Is(err, datamanage.ErrProfileExists) {
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, gin.H{"message": "Profile created successfully"})
+		})
+
+		profileGroup.GET("/:profile", func(ctx *gin.Context) {
+			profiles, err := dataService.ListProfiles()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			for _, profile := range profiles {
+				if profile.Name == ctx.Param("profile") {
+					ctx.JSON(http.StatusOK, gin.H{"message": profile})
+					return
+				}
+			}
+
+			ctx.JSON(http.StatusNotFound, gin.H{"error": datamanage.ErrProfileNotFound.Error()})
+		})
+
+		profileGroup.PUT("/:profile", func(ctx *gin.Context) {
+			var request dto.ProfileRequest
+
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			err := dataService.RenameProfile(ctx.Param("profile"), request.Name)
+			if errors.Is(err, datamanage.ErrProfileNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, datamanage.ErrProfileExists) {
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, gin.H{"message": "Profile renamed successfully"})
+		})
+
+		profileGroup.DELETE("/:profile", func(ctx *gin.Context) {
+			err := dataService.DeleteProfile(ctx.Param("profile"))
+			if errors.Is(err, datamanage.ErrProfileNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, datamanage.ErrDefaultProfile) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, gin.H{"message": "Profile deleted successfully"})
+		})
+	}
+
+	optionGroup := r.Group("/options")
+	{
+		optionGroup.GET("/passive-skills", func(ctx *gin.Context) {
+			result := options.GetPassiveSkills(dataService)
+
+			var passiveSkills []string
+			passiveSkills = append(passiveSkills, result...)
+
+			ctx.JSON(http.StatusOK, gin.H{"message": passiveSkills})
+		})
+
+		optionGroup.GET("/pal-species", func(ctx *gin.Context) {
+
+			result := options.GetPalSpecies(dataService)
+
+			var palSpecies []string
+			palSpecies = append(palSpecies, result...)
+
+			ctx.JSON(http.StatusOK, gin.H{"message": palSpecies})
+
+		})
+	}
+
+	adminGroup := r.Group("/admin")
+	{
+		adminGroup.GET("/validate/breeding", func(ctx *gin.Context) {
+			report, err := breedingService.ValidateBreedingData()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": report})
+		})
+
+		adminGroup.POST("/backups", func(ctx *gin.Context) {
+			backup, err := dataService.CreateBackup(datamanage.BackupReasonManual)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": backup})
+		})
+
+		adminGroup.GET("/backups", func(ctx *gin.Context) {
+			backups, err := dataService.ListBackups()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": backups})
+		})
+
+		adminGroup.POST("/backups/:id/restore", func(ctx *gin.Context) {
+			backup, err := dataService.RestoreBackup(ctx.Param("id"), datamanage.SourceAPI)
+			if errors.Is(err, datamanage.ErrBackupNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			// The restored paldex may differ from the one the index was built from
+			if err := breedingService.RebuildIndex(); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": backup})
+		})
+	}
+
+	// Start server on configured port
+	fmt.Printf("Starting server on port %s\n", cfg.Port)
+	r.Run(":" + cfg.Port)
+
+}
+
+// registerStoreRoutes adds the pal store and breeding routes, which work on the
+// profile picked by selectProfile
+func registerStoreRoutes(r gin.IRouter, dataService *datamanage.Service, breedingService *breeding.Service) {
 	r.POST("/add-pal", func(ctx *gin.Context) {
 		var pal dto.AddPalRequest
 
@@ -51,32 +274,49 @@ func main() {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		err := datamanage.AddPal(pal.Name, pal.Gender, pal.PassiveSkills)
+		id, err := dataService.AddPal(profileOf(ctx), pal.Name, models.StoredPal{
+			Gender:           pal.Gender,
+			Nickname:         pal.Nickname,
+			Level:            pal.Level,
+			Talents:          models.Talents(pal.Talents),
+			CondensationRank: pal.CondensationRank,
+			Container:        pal.Container,
+			PassiveSkills:    pal.PassiveSkills,
+			ActiveSkills:     pal.ActiveSkills,
+		}, datamanage.SourceAPI)
+		var validationErr *datamanage.ValidationError
+		if errors.As(err, &validationErr) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Pal added successfully"})
+		ctx.JSON(http.StatusOK, gin.H{"message": "Pal added successfully", "id": id})
 	})
 
 	r.GET("/store", func(ctx *gin.Context) {
-		result, err := datamanage.ReadStoredPals()
+		result, err := dataService.ReadStoredPals(profileOf(ctx))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 
 		}
 
-		palDex, err := datamanage.ReadPaldex()
-
-		// make map of palDex
-		palDexMap := make(map[string]models.Pal)
-		for _, pal := range palDex {
-			palDexMap[strings.ToLower(pal.Name)] = pal
-		}
-
 		var pals []dto.Pal
 		for _, species := range result {
+			// look up the image in the cached paldex
+			var imageUrl string
+			palDexEntry, err := dataService.FindPal(species.Name)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if palDexEntry != nil {
+				imageUrl = palDexEntry.ImageUrl
+			}
+
 			for _, pal := range species.StoredPals {
 
 				var passiveSkills []dto.PassiveSkill
@@ -86,12 +326,24 @@ func main() {
 					})
 				}
 
+				// pals stored before active skills were tracked have none
+				activeSkills := pal.ActiveSkills
+				if activeSkills == nil {
+					activeSkills = []string{}
+				}
+
 				pals = append(pals, dto.Pal{
-					Id:            pal.ID,
-					Name:          species.Name,
-					ImageUrl:      palDexMap[strings.ToLower(species.Name)].ImageUrl,
-					Gender:        pal.Gender,
-					PassiveSkills: passiveSkills,
+					Id:               pal.ID,
+					Name:             species.Name,
+					ImageUrl:         imageUrl,
+					Gender:           pal.Gender,
+					Nickname:         pal.Nickname,
+					Level:            pal.Level,
+					Talents:          dto.Talents(pal.Talents),
+					CondensationRank: pal.CondensationRank,
+					Container:        pal.Container,
+					PassiveSkills:    passiveSkills,
+					ActiveSkills:     activeSkills,
 				})
 			}
 		}
@@ -104,6 +356,137 @@ func main() {
 		ctx.JSON(http.StatusOK, gin.H{"message": pals})
 	})
 
+	r.GET("/store/export", func(ctx *gin.Context) {
+		format := strings.ToLower(ctx.DefaultQuery("format", datamanage.TransferFormatJSON))
+
+		data, err := dataService.ExportStore(profileOf(ctx), format)
+		if errors.Is(err, datamanage.ErrUnknownTransferFormat) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		contentType := "application/json; charset=utf-8"
+		if format == datamanage.TransferFormatCSV {
+			contentType = "text/csv; charset=utf-8"
+		}
+		ctx.Header("Content-Disposition", "attachment; filename=stored_pals."+format)
+		ctx.Data(http.StatusOK, contentType, data)
+	})
+
+	r.POST("/store/import", func(ctx *gin.Context) {
+		format := ctx.DefaultQuery("format", datamanage.TransferFormatJSON)
+		mode := ctx.DefaultQuery("mode", datamanage.ImportModeMerge)
+
+		data, err := ctx.GetRawData()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := dataService.ImportStore(profileOf(ctx), data, format, mode)
+		var importErr *datamanage.ImportError
+		if errors.As(err, &importErr) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "rows": importErr.Rows})
+			return
+		}
+		if errors.Is(err, datamanage.ErrUnknownTransferFormat) || errors.Is(err, datamanage.ErrUnknownImportMode) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": result})
+	})
+
+	r.POST("/store/import/save", func(ctx *gin.Context) {
+		mode := ctx.DefaultQuery("mode", datamanage.ImportModeMerge)
+
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		upload, err := file.Open()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer upload.Close()
+		data, err := io.ReadAll(upload)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := savefile.ImportLevelSave(dataService, profileOf(ctx), data, mode)
+		var importErr *datamanage.ImportError
+		if errors.As(err, &importErr) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "rows": importErr.Rows})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": result})
+	})
+
+	r.GET("/store/history", func(ctx *gin.Context) {
+		limit := 0
+		if value := ctx.Query("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+				return
+			}
+		}
+
+		history, err := dataService.StoreHistory(profileOf(ctx), limit)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": history})
+	})
+
+	r.POST("/store/undo", func(ctx *gin.Context) {
+		event, err := dataService.Undo(profileOf(ctx), datamanage.SourceAPI)
+		if errors.Is(err, datamanage.ErrNothingToUndo) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": event})
+	})
+
+	r.POST("/store/redo", func(ctx *gin.Context) {
+		event, err := dataService.Redo(profileOf(ctx), datamanage.SourceAPI)
+		if errors.Is(err, datamanage.ErrNothingToRedo) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": event})
+	})
+
 	r.DELETE("/remove-pal", func(ctx *gin.Context) {
 		var pal dto.RemovePalRequest
 
@@ -111,7 +494,11 @@ func main() {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		err := datamanage.RemovePal(pal.Name, pal.Id)
+		err := dataService.RemovePal(profileOf(ctx), pal.Id, datamanage.SourceAPI)
+		if errors.Is(err, datamanage.ErrStoredPalNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -119,33 +506,271 @@ func main() {
 		ctx.JSON(http.StatusOK, gin.H{"message": "Pal removed successfully"})
 	})
 
-	optionGroup := r.Group("/options")
+	// updatePal handles PUT and PATCH /pals/:id, replace is true for PUT
+	updatePal := func(replace bool) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			var pal dto.UpdatePalRequest
+
+			if err := ctx.ShouldBindJSON(&pal); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if replace {
+				if pal.Name == nil || pal.Gender == nil {
+					ctx.JSON(http.StatusBadRequest, gin.H{"error": "name and gender are required"})
+					return
+				}
+				resetMissingPalFields(&pal)
+			}
+
+			changes := datamanage.PalChanges{
+				Name:             pal.Name,
+				Gender:           pal.Gender,
+				Nickname:         pal.Nickname,
+				Level:            pal.Level,
+				CondensationRank: pal.CondensationRank,
+				Container:        pal.Container,
+				PassiveSkills:    pal.PassiveSkills,
+				ActiveSkills:     pal.ActiveSkills,
+			}
+			if pal.Talents != nil {
+				talents := models.Talents(*pal.Talents)
+				changes.Talents = &talents
+			}
+
+			err := dataService.UpdatePal(profileOf(ctx), ctx.Param("id"), changes, datamanage.SourceAPI)
+			if errors.Is(err, datamanage.ErrStoredPalNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			var validationErr *datamanage.ValidationError
+			if errors.As(err, &validationErr) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, gin.H{"message": "Pal updated successfully"})
+		}
+	}
+
+	palGroup := r.Group("/pals")
 	{
-		optionGroup.GET("/passive-skills", func(ctx *gin.Context) {
-			result := options.GetPassiveSkills()
+		palGroup.PUT("/:id", updatePal(true))
+		palGroup.PATCH("/:id", updatePal(false))
+	}
 
-			var passiveSkills []string
-			passiveSkills = append(passiveSkills, result...)
+	breedingGroup := r.Group("/breeding")
+	{
+		breedingGroup.GET("/chain", func(ctx *gin.Context) {
+			target := ctx.Query("target")
+			if target == "" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "target is required"})
+				return
+			}
 
-			ctx.JSON(http.StatusOK, gin.H{"message": passiveSkills})
+			chain, err := breedingService.FindParentChain(profileOf(ctx), target)
+			if errors.Is(err, breeding.ErrPalNotFound) || errors.Is(err, breeding.ErrNoChain) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": chain})
 		})
 
-		optionGroup.GET("/pal-species", func(ctx *gin.Context) {
+		breedingGroup.GET("/plan", func(ctx *gin.Context) {
+			target := ctx.Query("target")
+			if target == "" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "target is required"})
+				return
+			}
 
-			result := options.GetPalSpecies()
+			plan, err := breedingService.PlanBreeding(profileOf(ctx), target)
+			if errors.Is(err, breeding.ErrPalNotFound) || errors.Is(err, breeding.ErrNoChain) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
 
-			var palSpecies []string
-			palSpecies = append(palSpecies, result...)
+			ctx.JSON(http.StatusOK, gin.H{"message": plan})
+		})
 
-			ctx.JSON(http.StatusOK, gin.H{"message": palSpecies})
+		breedingGroup.GET("/parents/:species", func(ctx *gin.Context) {
+			parents, err := breedingService.FindParents(profileOf(ctx), ctx.Param("species"))
+			if errors.Is(err, breeding.ErrPalNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": parents})
+		})
+
+		breedingGroup.GET("/child", func(ctx *gin.Context) {
+			parent1 := ctx.Query("parent1")
+			parent2 := ctx.Query("parent2")
+			if parent1 == "" || parent2 == "" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "parent1 and parent2 are required"})
+				return
+			}
+
+			child, err := breedingService.FindChild(parent1, parent2)
+			if errors.Is(err, breeding.ErrPalNotFound) || errors.Is(err, breeding.ErrPairNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": child})
+		})
 
+		breedingGroup.POST("/simulate", func(ctx *gin.Context) {
+			var request dto.SimulateBreedingRequest
+
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			seed := int64(breeding.DefaultSimulationSeed)
+			if request.Seed != nil {
+				seed = *request.Seed
+			}
+
+			result, err := breedingService.SimulatePassives(request.ParentA, request.ParentB, request.Desired, request.Trials, seed)
+			if errors.Is(err, breeding.ErrPassiveSkillNotFound) || errors.Is(err, breeding.ErrTooManyPassives) || errors.Is(err, breeding.ErrTooManyTrials) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": result})
+		})
+
+		breedingGroup.POST("/goal", func(ctx *gin.Context) {
+			var request dto.GoalPlanRequest
+
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if request.Target == "" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "target is required"})
+				return
+			}
+
+			plan, err := breedingService.PlanGoal(ctx.Request.Context(), profileOf(ctx), request.Target, request.PassiveSkills, request.Combo)
+			if errors.Is(err, breeding.ErrPalNotFound) || errors.Is(err, breeding.ErrComboNotFound) || errors.Is(err, breeding.ErrNoGoalPlan) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, breeding.ErrGoalPassivesMissing) || errors.Is(err, breeding.ErrPassiveSkillNotFound) || errors.Is(err, breeding.ErrTooManyPassives) ||
+				errors.Is(err, breeding.ErrGoalSearchLimit) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": plan})
+		})
+
+		breedingGroup.GET("/available", func(ctx *gin.Context) {
+			children, err := breedingService.FindAvailableChildren(profileOf(ctx))
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"message": children})
+		})
+
+		breedingGroup.GET("/graph", func(ctx *gin.Context) {
+			format := ctx.DefaultQuery("format", breeding.GraphFormatDot)
+			depth := 0
+			if depthStr := ctx.Query("depth"); depthStr != "" {
+				var err error
+				depth, err = strconv.Atoi(depthStr)
+				if err != nil {
+					ctx.JSON(http.StatusBadRequest, gin.H{"error": "depth must be a number"})
+					return
+				}
+			}
+
+			graph, err := breedingService.ExportGraph(format, ctx.Query("root"), depth)
+			if errors.Is(err, breeding.ErrUnknownGraphFormat) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, breeding.ErrPalNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			contentType := "text/plain; charset=utf-8"
+			switch strings.ToLower(format) {
+			case breeding.GraphFormatDot:
+				contentType = "text/vnd.graphviz; charset=utf-8"
+			case breeding.GraphFormatGraphML:
+				contentType = "application/xml; charset=utf-8"
+			}
+			ctx.Data(http.StatusOK, contentType, []byte(graph))
 		})
 	}
+}
 
-	// Start server on configured port
-	fmt.Printf("Starting server on port %s\n", cfg.Port)
-	r.Run(":" + cfg.Port)
+// selectProfile picks the profile from the path, the X-Profile header or the default
+// profile, and stops the request if the profile doesn't exist
+func selectProfile(dataService *datamanage.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		profile := ctx.Param("profile")
+		if profile == "" {
+			profile = ctx.GetHeader(profileHeader)
+		}
+		if profile == "" {
+			profile = datamanage.DefaultProfile
+		}
+
+		exists, err := dataService.ProfileExists(profile)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": datamanage.ErrProfileNotFound.Error()})
+			return
+		}
+		ctx.Set(profileKey, profile)
+	}
+}
 
+// profileOf returns the profile picked by selectProfile
+func profileOf(ctx *gin.Context) string {
+	return ctx.GetString(profileKey)
 }
 
 func loading(function func() error) {
@@ -174,8 +799,14 @@ func loading(function func() error) {
 	done <- true // Send a signal to stop the spinner loop
 }
 
-func updateData() error {
-	err := scrapper.ScrapperPalInfo()
+func updateData(dataService *datamanage.Service, breedingService *breeding.Service) error {
+	// Keep the current data in case the scrape goes wrong
+	_, err := dataService.CreateBackup(datamanage.BackupReasonUpdateData)
+	if err != nil {
+		return err
+	}
+
+	err = scrapper.ScrapperPalInfo(dataService)
 	if err != nil {
 		return err
 	}
@@ -183,7 +814,7 @@ func updateData() error {
 	// Wait for 2 seconds before running the next function
 	time.Sleep(5 * time.Second)
 
-	err = scrapper.ScrapperPassiveSkill()
+	err = scrapper.ScrapperPassiveSkill(dataService)
 	if err != nil {
 		return err
 	}
@@ -191,15 +822,108 @@ func updateData() error {
 	// Wait for 2 seconds before running the next function
 	time.Sleep(5 * time.Second)
 
-	err = scrapper.BestComboPassiveSkill()
+	err = scrapper.BestComboPassiveSkill(dataService)
 	if err != nil {
 		return err
 	}
 
+	// Swap in a breeding index built from the fresh scrape
+	err = breedingService.RebuildIndex()
+	if err != nil {
+		return err
+	}
+
+	// Report problems in the fresh scrape without failing the update
+	report, err := breedingService.ValidateBreedingData()
+	if err != nil {
+		fmt.Println("Error validating breeding data:", err)
+		return nil
+	}
+	breeding.PrintValidationSummary(report)
+
+	return nil
+}
+
+// resetMissingPalFields sets every optional field missing from a PUT body to its zero value
+func resetMissingPalFields(pal *dto.UpdatePalRequest) {
+	if pal.Nickname == nil {
+		pal.Nickname = new(string)
+	}
+	if pal.Level == nil {
+		pal.Level = new(int)
+	}
+	if pal.Talents == nil {
+		pal.Talents = &dto.Talents{}
+	}
+	if pal.CondensationRank == nil {
+		pal.CondensationRank = new(int)
+	}
+	if pal.Container == nil {
+		pal.Container = new(string)
+	}
+	if pal.PassiveSkills == nil {
+		pal.PassiveSkills = []string{}
+	}
+	if pal.ActiveSkills == nil {
+		pal.ActiveSkills = []string{}
+	}
+}
+
+func runCommand(dataService *datamanage.Service, breedingService *breeding.Service, command string, args []string) error {
+	switch command {
+	case "validate-breeding":
+		report, err := breedingService.ValidateBreedingData()
+		if err != nil {
+			return err
+		}
+
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		breeding.PrintValidationSummary(report)
+	case "import-save":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: import-save <Level.sav> [merge|replace] [profile]")
+		}
+		mode := datamanage.ImportModeMerge
+		if len(args) > 1 {
+			mode = args[1]
+		}
+		profile := datamanage.DefaultProfile
+		if len(args) > 2 {
+			profile = args[2]
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		result, err := savefile.ImportLevelSave(dataService, profile, data, mode)
+		var importErr *datamanage.ImportError
+		if errors.As(err, &importErr) {
+			for _, row := range importErr.Rows {
+				fmt.Printf("  pal %d: %s\n", row.Row, row.Error)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+
 	return nil
 }
 
-func AddPalToStore() error {
+func AddPalToStore(dataService *datamanage.Service) error {
 	addPalReader := bufio.NewReader(os.Stdin)
 	fmt.Print("Pal name: ")
 	palName, _ := addPalReader.ReadString('\n')
@@ -208,9 +932,6 @@ func AddPalToStore() error {
 	fmt.Print("Pal gender (m/f): ")
 	palGender, _ := addPalReader.ReadString('\n')
 	palGender = strings.TrimSpace(palGender)
-	if palGender != "m" && palGender != "f" {
-		return fmt.Errorf("invalid pal gender")
-	}
 
 	passiveSkills := make([]string, 0)
 	fmt.Println("Please enter passive skill, enter empty to done input")
@@ -226,10 +947,11 @@ func AddPalToStore() error {
 
 	fmt.Println("Input is done")
 
-	err := datamanage.AddPal(palName, palGender, passiveSkills)
+	id, err := dataService.AddPal(datamanage.DefaultProfile, palName, models.StoredPal{Gender: palGender, PassiveSkills: passiveSkills}, datamanage.SourceCLI)
 	if err != nil {
 		return err
 	}
+	fmt.Println("Pal added with ID", id)
 
 	return nil
 }
@@ -1,11 +1,16 @@
 package models
 
-import "strings"
+import (
+	"strings"
+)
 
 type Pal struct {
 	Id          string
 	Name        string
 	ImageUrl    string
+	CombiRank   int
+	Rarity      int
+	Egg         string
 	Suitability []Suitability
 	Children    []Child
 }
@@ -26,10 +31,25 @@ type PalSpecies struct {
 }
 
 type StoredPal struct {
-	ID     int
-	Gender string
+	ID       string
+	Gender   string
+	Nickname string
+	Level    int
+	Talents  Talents
+	// CondensationRank is the number of stars from condensing duplicates, 0 to 4
+	CondensationRank int
+	// Container is where the pal is kept: "palbox", "party" or "base N"
+	Container string
 
 	PassiveSkills []string
+	ActiveSkills  []string
+}
+
+// Talents are the hidden HP, Attack and Defense IVs of a pal, 0 to 100 each
+type Talents struct {
+	HP      int
+	Attack  int
+	Defense int
 }
 
 func FindPal(pals []Pal, palName string) *Pal {
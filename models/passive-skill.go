@@ -8,6 +8,11 @@ type PassiveSkill struct {
 	Tier   int
 }
 
+type PassiveSkillCombo struct {
+	Name   string
+	Skills []string
+}
+
 func FindPassiveSkill(passiveSkill []PassiveSkill, skillName string) *PassiveSkill {
 	for _, v := range passiveSkill {
 		if strings.ToLower(v.Name) == strings.ToLower(skillName) {
@@ -17,3 +22,13 @@ func FindPassiveSkill(passiveSkill []PassiveSkill, skillName string) *PassiveSki
 
 	return nil
 }
+
+func FindPassiveSkillCombo(combos []PassiveSkillCombo, comboName string) *PassiveSkillCombo {
+	for _, v := range combos {
+		if strings.ToLower(v.Name) == strings.ToLower(comboName) {
+			return &v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// StoreEvent is one entry of the append-only pal store journal
+type StoreEvent struct {
+	ID     int
+	Time   time.Time
+	Action string
+	Source string
+	// Target is the event reverted by an undo or reapplied by a redo
+	Target  int
+	Changes []PalChange
+}
+
+// PalChange is the state of one stored pal before and after an event.
+// Before is nil for an added pal and After is nil for a removed pal.
+type PalChange struct {
+	PalID  string
+	Before *SpeciesPal
+	After  *SpeciesPal
+}
+
+// SpeciesPal is a stored pal together with its species
+type SpeciesPal struct {
+	Species string
+	Pal     StoredPal
+}
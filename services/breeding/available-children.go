@@ -0,0 +1,77 @@
+package breeding
+
+import (
+	"palworld_tools/dto"
+	"sort"
+	"strings"
+)
+
+// FindAvailableChildren pairs every male with every female stored in profile and
+// returns the distinct child species grouped with the pairings that produce them.
+// Species not in the store yet are listed first.
+func (s *Service) FindAvailableChildren(profile string) ([]dto.AvailableChild, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	palStore, err := s.dataService.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool)
+	males := make([]dto.StoredPalRef, 0)
+	females := make([]dto.StoredPalRef, 0)
+	for _, species := range palStore {
+		name := data.canonicalName(species.Name)
+		for _, pal := range species.StoredPals {
+			owned[strings.ToLower(name)] = true
+			ref := dto.StoredPalRef{Species: name, PalId: pal.ID}
+			switch strings.ToLower(pal.Gender) {
+			case "m":
+				males = append(males, ref)
+			case "f":
+				females = append(females, ref)
+			}
+		}
+	}
+
+	byChild := make(map[string]*dto.AvailableChild)
+	for _, male := range males {
+		for _, female := range females {
+			child, ok := data.child(male.Species, female.Species)
+			if !ok {
+				continue
+			}
+
+			key := strings.ToLower(child)
+			available := byChild[key]
+			if available == nil {
+				available = &dto.AvailableChild{
+					Child: child,
+					Owned: owned[key],
+					Pairs: make([]dto.StoredPalPair, 0),
+				}
+				if pal := data.findPal(child); pal != nil {
+					available.ImageUrl = pal.ImageUrl
+				}
+				byChild[key] = available
+			}
+			available.Pairs = append(available.Pairs, dto.StoredPalPair{Male: male, Female: female})
+		}
+	}
+
+	result := make([]dto.AvailableChild, 0, len(byChild))
+	for _, available := range byChild {
+		result = append(result, *available)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Owned != result[j].Owned {
+			return !result[i].Owned
+		}
+		return result[i].Child < result[j].Child
+	})
+
+	return result, nil
+}
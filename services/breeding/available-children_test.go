@@ -0,0 +1,60 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"reflect"
+	"testing"
+)
+
+func TestFindAvailableChildren(t *testing.T) {
+	pals := []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+		{Name: "B"},
+		{Name: "C", ImageUrl: "c.png"},
+	}
+	service, dataService := newTestService(t, pals, nil)
+	if err := dataService.CreateProfile("males"); err != nil {
+		t.Fatalf("CreateProfile: %v", err)
+	}
+
+	add := func(profile string, species string, gender string) dto.StoredPalRef {
+		t.Helper()
+		id, err := dataService.AddPal(profile, species, models.StoredPal{Gender: gender}, datamanage.SourceAPI)
+		if err != nil {
+			t.Fatalf("AddPal: %v", err)
+		}
+		return dto.StoredPalRef{Species: species, PalId: id}
+	}
+	maleA := add(datamanage.DefaultProfile, "A", "m")
+	femaleA := add(datamanage.DefaultProfile, "A", "f")
+	femaleB := add(datamanage.DefaultProfile, "B", "f")
+	add("males", "A", "m")
+	add("males", "B", "m")
+
+	cases := []struct {
+		profile string
+		want    []dto.AvailableChild
+		err     error
+	}{
+		// Species not stored yet come first
+		{datamanage.DefaultProfile, []dto.AvailableChild{
+			{Child: "C", ImageUrl: "c.png", Pairs: []dto.StoredPalPair{{Male: maleA, Female: femaleB}}},
+			{Child: "A", Owned: true, Pairs: []dto.StoredPalPair{{Male: maleA, Female: femaleA}}},
+		}, nil},
+		{"males", []dto.AvailableChild{}, nil},
+		{"missing", nil, datamanage.ErrProfileNotFound},
+	}
+	for _, c := range cases {
+		children, err := service.FindAvailableChildren(c.profile)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: got error %v, want %v", c.profile, err, c.err)
+			continue
+		}
+		if !reflect.DeepEqual(children, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.profile, children, c.want)
+		}
+	}
+}
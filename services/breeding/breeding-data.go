@@ -0,0 +1,179 @@
+package breeding
+
+import (
+	"palworld_tools/models"
+	"strings"
+)
+
+// pairKey identifies an unordered parent pair, so A+B and B+A share a key
+type pairKey struct {
+	a string
+	b string
+}
+
+func newPairKey(parentA string, parentB string) pairKey {
+	a := strings.ToLower(parentA)
+	b := strings.ToLower(parentB)
+	if a > b {
+		a, b = b, a
+	}
+	return pairKey{a: a, b: b}
+}
+
+// breedingPair is a scraped parent pair with paldex spelling for every name
+type breedingPair struct {
+	parentA string
+	parentB string
+	child   string
+}
+
+// Where a computed child comes from, reported alongside breeding results
+const (
+	sourceScraped       = "scraped"
+	sourceUniqueCombo   = "unique_combo"
+	sourceSameSpecies   = "same_species"
+	sourceBreedingPower = "breeding_power"
+)
+
+// matrixCell is the precomputed child of one parent pair
+type matrixCell struct {
+	child  string
+	source string
+}
+
+// breedingData holds the paldex indexed by name, the scraped parent pairs and
+// what is needed to compute the child of pairs the scraped tables don't list.
+// It is never modified after buildBreedingData returns, so it is safe to share.
+type breedingData struct {
+	pals     []models.Pal
+	byName   map[string]*models.Pal
+	position map[string]int
+	pairs    map[pairKey]breedingPair
+	unique   map[pairKey]breedingPair
+	ranked   []models.Pal
+	matrix   [][]matrixCell
+}
+
+// buildBreedingData indexes the paldex and precomputes the child of every parent pair
+func buildBreedingData(pals []models.Pal) *breedingData {
+	pals = withCombiRanks(pals)
+	data := &breedingData{
+		pals:     pals,
+		byName:   make(map[string]*models.Pal),
+		position: make(map[string]int),
+		pairs:    make(map[pairKey]breedingPair),
+		unique:   make(map[pairKey]breedingPair),
+		ranked:   rankedPals(pals),
+	}
+	for i := range pals {
+		data.byName[strings.ToLower(pals[i].Name)] = &pals[i]
+		data.position[strings.ToLower(pals[i].Name)] = i
+	}
+
+	// Each Children entry on pal X means X + Parent = Child
+	for _, pal := range pals {
+		for _, child := range pal.Children {
+			if !isScrapedPair(pal.Name, child) {
+				continue
+			}
+			key := newPairKey(pal.Name, child.Parent)
+			if _, exists := data.pairs[key]; exists {
+				continue
+			}
+			data.pairs[key] = breedingPair{
+				parentA: pal.Name,
+				parentB: data.canonicalName(child.Parent),
+				child:   data.canonicalName(child.Child),
+			}
+		}
+	}
+
+	for _, combo := range uniqueCombos {
+		data.unique[newPairKey(combo.parentA, combo.parentB)] = breedingPair{
+			parentA: data.canonicalName(combo.parentA),
+			parentB: data.canonicalName(combo.parentB),
+			child:   data.canonicalName(combo.child),
+		}
+	}
+
+	// The matrix is symmetric, so compute each pair once and mirror it
+	data.matrix = make([][]matrixCell, len(pals))
+	for i := range pals {
+		data.matrix[i] = make([]matrixCell, len(pals))
+	}
+	for i := range pals {
+		for j := i; j < len(pals); j++ {
+			child, source := data.computeChild(pals[i].Name, pals[j].Name)
+			data.matrix[i][j] = matrixCell{child: child, source: source}
+			data.matrix[j][i] = data.matrix[i][j]
+		}
+	}
+
+	return data
+}
+
+// isScrapedPair reports whether a Children entry of pal is a real parent pair. The scraped
+// tables of the newer pals list the pal itself as the other parent of every row, but two
+// pals of one species always hatch that species, so those rows are dropped.
+func isScrapedPair(pal string, child models.Child) bool {
+	return !strings.EqualFold(pal, child.Parent) || strings.EqualFold(pal, child.Child)
+}
+
+// findPal returns the paldex entry for name, ignoring case
+func (d *breedingData) findPal(name string) *models.Pal {
+	return d.byName[strings.ToLower(name)]
+}
+
+// canonicalName returns the paldex spelling of name, or name itself if unknown
+func (d *breedingData) canonicalName(name string) string {
+	if pal := d.findPal(name); pal != nil {
+		return pal.Name
+	}
+	return name
+}
+
+// child returns the species produced by breeding parentA with parentB
+func (d *breedingData) child(parentA string, parentB string) (string, bool) {
+	child, source := d.resolveChild(parentA, parentB)
+	return child, source != ""
+}
+
+// resolveChild returns the child of parentA and parentB and where it came from.
+// The source is empty when no rule applies.
+func (d *breedingData) resolveChild(parentA string, parentB string) (string, string) {
+	i, okA := d.position[strings.ToLower(parentA)]
+	j, okB := d.position[strings.ToLower(parentB)]
+	if okA && okB {
+		cell := d.matrix[i][j]
+		return cell.child, cell.source
+	}
+
+	// Parents outside the paldex can still appear in scraped pairs
+	return d.computeChild(parentA, parentB)
+}
+
+// computeChild applies the breeding rules in order: scraped pairs win, then
+// unique combinations, the same-species rule and finally the breeding power formula
+func (d *breedingData) computeChild(parentA string, parentB string) (string, string) {
+	key := newPairKey(parentA, parentB)
+	if pair, ok := d.pairs[key]; ok {
+		return pair.child, sourceScraped
+	}
+	if pair, ok := d.unique[key]; ok {
+		return pair.child, sourceUniqueCombo
+	}
+
+	palA := d.findPal(parentA)
+	palB := d.findPal(parentB)
+	if palA == nil || palB == nil {
+		return "", ""
+	}
+	if palA.Name == palB.Name {
+		return palA.Name, sourceSameSpecies
+	}
+	if child, ok := childByBreedingPower(d.ranked, palA, palB); ok {
+		return child, sourceBreedingPower
+	}
+
+	return "", ""
+}
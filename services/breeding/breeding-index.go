@@ -0,0 +1,52 @@
+package breeding
+
+import (
+	"fmt"
+	"palworld_tools/services/datamanage"
+	"sync"
+	"sync/atomic"
+)
+
+// Service answers breeding queries from the paldex and pal stores of a datamanage service
+type Service struct {
+	dataService *datamanage.Service
+
+	// index is the breeding data every query reads from. It is replaced as a
+	// whole on rebuild, so readers always see either the old or the new index.
+	index        atomic.Pointer[breedingData]
+	rebuildMutex sync.Mutex
+}
+
+func NewService(dataService *datamanage.Service) *Service {
+	return &Service{dataService: dataService}
+}
+
+// RebuildIndex reads the paldex, precomputes the breeding matrix and swaps it in.
+// Call it at startup and whenever the scraped data changes.
+func (s *Service) RebuildIndex() error {
+	s.rebuildMutex.Lock()
+	defer s.rebuildMutex.Unlock()
+
+	pals, err := s.dataService.Paldex()
+	if err != nil {
+		return err
+	}
+
+	s.index.Store(buildBreedingData(pals))
+	fmt.Println("Breeding index rebuilt for", len(pals), "pals")
+
+	return nil
+}
+
+// loadBreedingData returns the current breeding index, building it on first use
+func (s *Service) loadBreedingData() (*breedingData, error) {
+	if data := s.index.Load(); data != nil {
+		return data, nil
+	}
+
+	if err := s.RebuildIndex(); err != nil {
+		return nil, err
+	}
+
+	return s.index.Load(), nil
+}
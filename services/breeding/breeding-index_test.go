@@ -0,0 +1,99 @@
+package breeding
+
+import (
+	"fmt"
+	"palworld_tools/models"
+	"sync"
+	"testing"
+)
+
+// indexTestPals breeds A+B into child, so each paldex version can be told apart
+func indexTestPals(child string) []models.Pal {
+	return []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: child}}},
+		{Name: "B"},
+		{Name: "C"},
+		{Name: "D"},
+	}
+}
+
+// TestRebuildIndexUnderConcurrentReads runs with -race. Readers must always see a whole
+// index, either the one before a rebuild or the one after it.
+func TestRebuildIndexUnderConcurrentReads(t *testing.T) {
+	const (
+		readers  = 8
+		reads    = 200
+		rebuilds = 20
+	)
+
+	service, dataService := newTestService(t, indexTestPals("C"), nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, readers*reads+rebuilds)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < reads; j++ {
+				child, err := service.FindChild("A", "B")
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if child.Name != "C" && child.Name != "D" {
+					errs <- fmt.Errorf("got child %s, want C or D", child.Name)
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rebuilds; i++ {
+			child := []string{"C", "D"}[i%2]
+			if err := dataService.WritePaldex(indexTestPals(child)); err != nil {
+				errs <- err
+				continue
+			}
+			if err := service.RebuildIndex(); err != nil {
+				errs <- err
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent read failed: %v", err)
+	}
+
+	// The last rebuild wrote D, and every reader sees it now
+	child, err := service.FindChild("A", "B")
+	if err != nil || child.Name != "D" {
+		t.Errorf("after the rebuilds got %+v, %v, want D", child, err)
+	}
+}
+
+// TestFirstReadsBuildTheIndex starts many readers on a service whose index was never built
+func TestFirstReadsBuildTheIndex(t *testing.T) {
+	_, dataService := newTestService(t, indexTestPals("C"), nil)
+	service := NewService(dataService)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if child, err := service.FindChild("A", "B"); err != nil || child.Name != "C" {
+				errs <- fmt.Errorf("got %+v, %v, want C", child, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
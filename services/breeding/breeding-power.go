@@ -0,0 +1,217 @@
+package breeding
+
+import (
+	"palworld_tools/models"
+	"sort"
+	"strings"
+)
+
+// uniqueCombos are parent pairs whose child is fixed by the game rather than
+// computed from breeding power
+var uniqueCombos = []breedingPair{
+	{parentA: "Relaxaurus", parentB: "Sparkit", child: "Relaxaurus Lux"},
+	{parentA: "Incineram", parentB: "Maraith", child: "Incineram Noct"},
+	{parentA: "Mau", parentB: "Pengullet", child: "Mau Cryst"},
+	{parentA: "Vanwyrm", parentB: "Foxcicle", child: "Vanwyrm Cryst"},
+	{parentA: "Eikthyrdeer", parentB: "Hangyu", child: "Eikthyrdeer Terra"},
+	{parentA: "Elphidran", parentB: "Surfent", child: "Elphidran Aqua"},
+	{parentA: "Pyrin", parentB: "Katress", child: "Pyrin Noct"},
+	{parentA: "Mammorest", parentB: "Wumpo", child: "Mammorest Cryst"},
+	{parentA: "Mossanda", parentB: "Grizzbolt", child: "Mossanda Lux"},
+	{parentA: "Dinossom", parentB: "Rayhound", child: "Dinossom Lux"},
+	{parentA: "Jolthog", parentB: "Pengullet", child: "Jolthog Cryst"},
+	{parentA: "Frostallion", parentB: "Helzephyr", child: "Frostallion Noct"},
+	{parentA: "Kingpaca", parentB: "Reindrix", child: "Kingpaca Cryst"},
+	{parentA: "Lyleen", parentB: "Menasting", child: "Lyleen Noct"},
+	{parentA: "Leezpunk", parentB: "Flambelle", child: "Leezpunk Ignis"},
+	{parentA: "Blazehowl", parentB: "Felbat", child: "Blazehowl Noct"},
+	{parentA: "Robinquill", parentB: "Fuddler", child: "Robinquill Terra"},
+	{parentA: "Broncherry", parentB: "Fuack", child: "Broncherry Aqua"},
+	{parentA: "Surfent", parentB: "Dumud", child: "Surfent Terra"},
+	{parentA: "Gobfin", parentB: "Rooby", child: "Gobfin Ignis"},
+	{parentA: "Suzaku", parentB: "Jormuntide", child: "Suzaku Aqua"},
+	{parentA: "Reptyro", parentB: "Foxcicle", child: "Reptyro Cryst"},
+	{parentA: "Hangyu", parentB: "Swee", child: "Hangyu Cryst"},
+	{parentA: "Mossanda", parentB: "Petallia", child: "Lyleen"},
+	{parentA: "Vanwyrm", parentB: "Anubis", child: "Faleris"},
+	{parentA: "Mossanda", parentB: "Rayhound", child: "Grizzbolt"},
+	{parentA: "Grizzbolt", parentB: "Relaxaurus", child: "Orserk"},
+	{parentA: "Kitsun", parentB: "Astegon", child: "Shadowbeak"},
+	{parentA: "Jormuntide", parentB: "Blazehowl", child: "Jormuntide Ignis"},
+	{parentA: "Penking", parentB: "Bushi", child: "Anubis"},
+	{parentA: "Kelpsea", parentB: "Foxparks", child: "Kelpsea Ignis"},
+	{parentA: "Caprity", parentB: "Tarantriss", child: "Caprity Noct"},
+	{parentA: "Fenglope", parentB: "Azurmane", child: "Fenglope Lux"},
+	{parentA: "Menasting", parentB: "Knocklem", child: "Menasting Terra"},
+}
+
+// sameSpeciesOnly are the legendary pals, which only hatch from two of their own
+// species and are never the result of the breeding power formula
+var sameSpeciesOnly = map[string]bool{
+	"Jetragon": true, "Frostallion": true, "Frostallion Noct": true, "Paladius": true,
+	"Necromus": true, "Bellanoir": true, "Bellanoir Libero": true, "Xenolord": true,
+	"Neptilius": true,
+}
+
+// comboOnlyVariants are element variants that only hatch from a unique combination
+// whose parents aren't in uniqueCombos. The scraped pairs never produce them where
+// the breeding power formula would, so they are left out of the formula results.
+var comboOnlyVariants = map[string]bool{
+	"Foxparks Cryst": true, "Dumud Gild": true, "Kitsun Noct": true, "Bushi Noct": true,
+	"Katress Ignis": true, "Cryolinx Terra": true, "Helzephyr Lux": true, "Croajiro Noct": true,
+}
+
+// unbreedablePals are the Terraria pals, which can't be bred and have no breeding power
+var unbreedablePals = map[string]bool{
+	"Purple Slime": true, "Cave Bat": true, "Illuminant Slime": true, "Illuminant Bat": true,
+	"Rainbow Slime": true, "Green Slime": true, "Enchanted Sword": true, "Eye of Cthulu": true,
+	"Demon Eye": true, "Blue Slime": true, "Red Slime": true,
+}
+
+// combiRanks is the breeding power of every breedable pal in the shipped paldex, used for
+// pals the scraped paldex has no breeding power for. The pals added after the original
+// release are listed after Jetragon, their ranks are fitted to the scraped parent pairs.
+var combiRanks = map[string]int{
+	"Lamball": 1470, "Cattiva": 1460, "Chikipi": 1500, "Lifmunk": 1430,
+	"Foxparks": 1400, "Fuack": 1330, "Sparkit": 1410, "Tanzee": 1250,
+	"Rooby": 1155, "Pengullet": 1350, "Penking": 520, "Jolthog": 1370,
+	"Jolthog Cryst": 1360, "Gumoss": 1240, "Vixy": 1450, "Hoocrates": 1390,
+	"Teafant": 1490, "Depresso": 1380, "Cremis": 1455, "Daedream": 1230,
+	"Rushoar": 1130, "Nox": 1180, "Fuddler": 1220, "Killamari": 1290,
+	"Mau": 1480, "Mau Cryst": 1440, "Celaray": 870, "Direhowl": 1060,
+	"Tocotoco": 1340, "Flopie": 1280, "Mozzarina": 910, "Bristla": 1320,
+	"Gobfin": 1090, "Gobfin Ignis": 1100, "Hangyu": 1420, "Hangyu Cryst": 1422,
+	"Mossanda": 430, "Mossanda Lux": 390, "Woolipop": 1190, "Caprity": 930,
+	"Melpaca": 890, "Eikthyrdeer": 920, "Eikthyrdeer Terra": 900, "Nitewing": 420,
+	"Ribbuny": 1310, "Incineram": 590, "Incineram Noct": 580, "Cinnamoth": 490,
+	"Arsox": 790, "Dumud": 895, "Cawgnito": 1080, "Leezpunk": 1120,
+	"Leezpunk Ignis": 1140, "Loupmoon": 950, "Galeclaw": 1030, "Robinquill": 1020,
+	"Robinquill Terra": 1000, "Gorirat": 1040, "Beegarde": 1070, "Elizabee": 330,
+	"Grintale": 510, "Swee": 1300, "Sweepa": 410, "Chillet": 800,
+	"Univolt": 680, "Foxcicle": 760, "Pyrin": 360, "Pyrin Noct": 240,
+	"Reindrix": 880, "Rayhound": 740, "Kitsun": 830, "Dazzi": 1210,
+	"Lunaris": 1110, "Dinossom": 820, "Dinossom Lux": 810, "Surfent": 560,
+	"Surfent Terra": 550, "Maraith": 1150, "Digtoise": 850, "Tombat": 750,
+	"Lovander": 940, "Flambelle": 1405, "Vanwyrm": 660, "Vanwyrm Cryst": 620,
+	"Bushi": 640, "Beakon": 220, "Ragnahawk": 380, "Katress": 700,
+	"Wixen": 1160, "Verdash": 990, "Vaelet": 1050, "Sibelyx": 450,
+	"Elphidran": 540, "Elphidran Aqua": 530, "Kelpsea": 1260, "Kelpsea Ignis": 1270,
+	"Azurobe": 500, "Cryolinx": 130, "Blazehowl": 710, "Blazehowl Noct": 670,
+	"Relaxaurus": 280, "Relaxaurus Lux": 270, "Broncherry": 860, "Broncherry Aqua": 840,
+	"Petallia": 780, "Reptyro": 320, "Reptyro Cryst": 230, "Kingpaca": 470,
+	"Kingpaca Cryst": 440, "Mammorest": 300, "Mammorest Cryst": 290, "Wumpo": 460,
+	"Wumpo Botan": 480, "Warsect": 340, "Fenglope": 980, "Felbat": 1010,
+	"Quivern": 350, "Blazamut": 10, "Helzephyr": 190, "Astegon": 150,
+	"Menasting": 260, "Anubis": 570, "Jormuntide": 310, "Jormuntide Ignis": 315,
+	"Suzaku": 50, "Suzaku Aqua": 30, "Grizzbolt": 200, "Lyleen": 250,
+	"Lyleen Noct": 210, "Faleris": 370, "Orserk": 140, "Shadowbeak": 60,
+	"Paladius": 80, "Necromus": 70, "Frostallion": 120, "Frostallion Noct": 100,
+	"Jetragon": 90,
+
+	"Foxparks Cryst": 1306, "Fuack Ignis": 1290, "Pengullet Lux": 1310, "Penking Lux": 490,
+	"Special Flower Gumoss": 1241, "Killamari Primo": 1250, "Celaray Lux": 830,
+	"Caprity Noct": 860, "Ribbuny Botan": 1210, "Dumud Gild": 855, "Loupmoon Cryst": 800,
+	"Gorirat Terra": 1030, "Chillet Ignis": 790, "Kitsun Noct": 735, "Dazzi Noct": 1120,
+	"Bushi Noct": 650, "Katress Ignis": 691, "Wixen Noct": 1151, "Azurobe Cryst": 480,
+	"Cryolinx Terra": 160, "Warsect Terra": 280, "Fenglope Lux": 830, "Quivern Botan": 340,
+	"Blazamut Ryu": 9, "Helzephyr Lux": 180, "Menasting Terra": 250, "Faleris Aqua": 260,
+	"Bellanoir": 1, "Bellanoir Libero": 1, "Selyne": 340, "Croajiro": 795,
+	"Croajiro Noct": 770, "Lullu": 905, "Shroomer": 720, "Shroomer Noct": 730,
+	"Kikit": 1125, "Sootseer": 545, "Prixter": 355, "Knocklem": 265, "Yakumo": 945,
+	"Dogen": 665, "Dazemu": 675, "Mimog": 1200, "Xenovader": 470, "Xenogard": 430,
+	"Xenolord": 265, "Nitemary": 705, "Starryon": 365, "Silvegis": 215, "Smokie": 1250,
+	"Celesdir": 820, "Omascul": 629, "Splatterina": 725, "Tarantriss": 825, "Azurmane": 400,
+	"Bastigor": 150, "Prunelia": 760, "Nyafia": 645, "Gildane": 505, "Herbil": 1450,
+	"Icelyn": 629, "Frostplume": 660, "Palumba": 460, "Braloha": 335, "Munchill": 1335,
+	"Polapup": 750, "Turtacle": 1110, "Turtacle Terra": 1070, "Jellroy": 1395,
+	"Jelliette": 1385, "Gloopie": 1200, "Finsider": 1295, "Finsider Ignis": 1260,
+	"Ghangler": 520, "Ghangler Ignis": 510, "Whalaska": 450, "Whalaska Ignis": 430,
+	"Neptilius": 90,
+}
+
+// withCombiRanks returns a copy of pals where missing breeding power is taken from combiRanks
+func withCombiRanks(pals []models.Pal) []models.Pal {
+	known := make(map[string]int, len(combiRanks))
+	for name, rank := range combiRanks {
+		known[strings.ToLower(name)] = rank
+	}
+
+	ranked := make([]models.Pal, len(pals))
+	copy(ranked, pals)
+	for i := range ranked {
+		if ranked[i].CombiRank <= 0 {
+			ranked[i].CombiRank = known[strings.ToLower(ranked[i].Name)]
+		}
+	}
+	return ranked
+}
+
+// rankedPals returns the pals the breeding power formula can produce, sorted by CombiRank.
+// Pals without a breeding power are left out, and so are legendaries and pals only
+// obtainable through a unique combination. A unique child that scraped pairs of other
+// parents also produce, like Anubis, stays in.
+func rankedPals(pals []models.Pal) []models.Pal {
+	excluded := make(map[string]bool)
+	for name := range sameSpeciesOnly {
+		excluded[strings.ToLower(name)] = true
+	}
+	for name := range comboOnlyVariants {
+		excluded[strings.ToLower(name)] = true
+	}
+
+	uniqueChildren := make(map[string]bool)
+	uniquePairs := make(map[pairKey]bool)
+	for _, combo := range uniqueCombos {
+		uniqueChildren[strings.ToLower(combo.child)] = true
+		uniquePairs[newPairKey(combo.parentA, combo.parentB)] = true
+	}
+	for _, pal := range pals {
+		for _, child := range pal.Children {
+			if uniquePairs[newPairKey(pal.Name, child.Parent)] || !isScrapedPair(pal.Name, child) ||
+				strings.EqualFold(pal.Name, child.Child) || strings.EqualFold(child.Parent, child.Child) {
+				continue
+			}
+			delete(uniqueChildren, strings.ToLower(child.Child))
+		}
+	}
+
+	ranked := make([]models.Pal, 0, len(pals))
+	for _, pal := range pals {
+		name := strings.ToLower(pal.Name)
+		if pal.CombiRank <= 0 || uniqueChildren[name] || excluded[name] {
+			continue
+		}
+		ranked = append(ranked, pal)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].CombiRank < ranked[j].CombiRank
+	})
+
+	return ranked
+}
+
+// childByBreedingPower applies the game formula: the child is the pal whose CombiRank
+// is closest to the average of both parents, with ties going to the lower CombiRank
+func childByBreedingPower(ranked []models.Pal, parentA *models.Pal, parentB *models.Pal) (string, bool) {
+	if len(ranked) == 0 || parentA.CombiRank <= 0 || parentB.CombiRank <= 0 {
+		return "", false
+	}
+
+	target := (parentA.CombiRank + parentB.CombiRank + 1) / 2
+
+	idx := sort.Search(len(ranked), func(i int) bool {
+		return ranked[i].CombiRank >= target
+	})
+
+	if idx < len(ranked) && (idx == 0 || ranked[idx].CombiRank-target < target-ranked[idx-1].CombiRank) {
+		return ranked[idx].Name, true
+	}
+
+	// Several pals can share a CombiRank, take the first one listed at that rank
+	rank := ranked[idx-1].CombiRank
+	for idx > 1 && ranked[idx-2].CombiRank == rank {
+		idx--
+	}
+	return ranked[idx-1].Name, true
+}
@@ -0,0 +1,153 @@
+package breeding
+
+import (
+	"os"
+	"palworld_tools/config"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"path/filepath"
+	"testing"
+)
+
+func TestBreedingPowerWithoutScrapedRanks(t *testing.T) {
+	// The shipped paldex has no breeding power, so the embedded ranks are used
+	data := buildBreedingData([]models.Pal{{Name: "Lamball"}, {Name: "Cattiva"}, {Name: "Cremis"}})
+
+	child, source := data.resolveChild("Lamball", "Cattiva")
+	if child != "Cattiva" || source != sourceBreedingPower {
+		t.Errorf("got %s from %s, want Cattiva from %s", child, source, sourceBreedingPower)
+	}
+}
+
+func TestRankedPalsKeepsUniqueChildrenOfOrdinaryPairs(t *testing.T) {
+	pals := []models.Pal{
+		{Name: "Penking", CombiRank: 520},
+		{Name: "Bushi", CombiRank: 640, Children: []models.Child{{Parent: "Penking", Child: "Anubis"}}},
+		{Name: "Anubis", CombiRank: 570},
+		{Name: "Lyleen", CombiRank: 250},
+	}
+	if containsPal(rankedPals(pals), "Anubis") {
+		t.Errorf("Anubis is ranked although only its unique combination produces it")
+	}
+
+	pals[0].Children = []models.Child{{Parent: "Lyleen", Child: "Anubis"}}
+	ranked := rankedPals(pals)
+	if !containsPal(ranked, "Anubis") {
+		t.Errorf("Anubis is left out although Penking + Lyleen produce it")
+	}
+	if containsPal(ranked, "Lyleen") {
+		t.Errorf("Lyleen is ranked although only its unique combination produces it")
+	}
+}
+
+func TestShippedPaldexIsRanked(t *testing.T) {
+	data := buildBreedingData(shippedPaldex(t))
+
+	for _, pal := range data.pals {
+		if pal.CombiRank <= 0 && !unbreedablePals[pal.Name] {
+			t.Errorf("%s has no breeding power", pal.Name)
+		}
+	}
+	for _, combo := range uniqueCombos {
+		for _, name := range []string{combo.parentA, combo.parentB, combo.child} {
+			if data.findPal(name) == nil {
+				t.Errorf("unique combination pal %s is not in the paldex", name)
+			}
+		}
+	}
+	for _, names := range []map[string]bool{sameSpeciesOnly, comboOnlyVariants, unbreedablePals} {
+		for name := range names {
+			if data.findPal(name) == nil {
+				t.Errorf("%s is not in the paldex", name)
+			}
+		}
+	}
+}
+
+func TestShippedPaldexBreeding(t *testing.T) {
+	data := buildBreedingData(shippedPaldex(t))
+
+	tests := []struct {
+		parentA string
+		parentB string
+		child   string
+		source  string
+	}{
+		{"Kelpsea", "Foxparks", "Kelpsea Ignis", sourceUniqueCombo},
+		{"Menasting", "Knocklem", "Menasting Terra", sourceUniqueCombo},
+		{"Xenolord", "Xenolord", "Xenolord", sourceSameSpecies},
+		{"Frostallion", "Helzephyr", "Frostallion Noct", sourceUniqueCombo},
+	}
+	for _, tt := range tests {
+		child, source := data.resolveChild(tt.parentA, tt.parentB)
+		if child != tt.child || source != tt.source {
+			t.Errorf("%s + %s = %s from %s, want %s from %s", tt.parentA, tt.parentB, child, source, tt.child, tt.source)
+		}
+	}
+
+	if _, ok := data.child("Lamball", "Foxparks Cryst"); !ok {
+		t.Errorf("Lamball + Foxparks Cryst has no child")
+	}
+
+	// Legendaries and combination-only variants never come from the formula
+	for i := range data.matrix {
+		for j := range data.matrix[i] {
+			cell := data.matrix[i][j]
+			if cell.source == sourceBreedingPower && (sameSpeciesOnly[cell.child] || comboOnlyVariants[cell.child]) {
+				t.Errorf("%s + %s = %s from the breeding power formula", data.pals[i].Name, data.pals[j].Name, cell.child)
+			}
+		}
+	}
+}
+
+func TestScrapedPairsWithTheParentItselfAreIgnored(t *testing.T) {
+	data := buildBreedingData([]models.Pal{
+		{Name: "Xenolord", Children: []models.Child{{Parent: "Xenolord", Child: "Grintale"}}},
+		{Name: "Grintale"},
+	})
+
+	child, source := data.resolveChild("Xenolord", "Xenolord")
+	if child != "Xenolord" || source != sourceSameSpecies {
+		t.Errorf("got %s from %s, want Xenolord from %s", child, source, sourceSameSpecies)
+	}
+}
+
+// shippedPaldex reads data/pals.json through a data service over a copy of it
+func shippedPaldex(t *testing.T) []models.Pal {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("..", "..", "data", "pals.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pals.json"), content, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	dataService, err := datamanage.NewService(&config.Config{
+		DataDir:                dir,
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	pals, err := dataService.Paldex()
+	if err != nil {
+		t.Fatalf("Paldex: %v", err)
+	}
+	return pals
+}
+
+func containsPal(pals []models.Pal, name string) bool {
+	for _, pal := range pals {
+		if pal.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package breeding
+
+import (
+	"palworld_tools/models"
+	"strings"
+)
+
+// Each egg laid at the Breeding Farm consumes one cake
+const cakesPerEgg = 1
+
+const (
+	eggSizeNormal = "normal"
+	eggSizeLarge  = "large"
+	eggSizeHuge   = "huge"
+)
+
+// incubationMinutes is the base incubation time per egg size at default world settings
+var incubationMinutes = map[string]float64{
+	eggSizeNormal: 5,
+	eggSizeLarge:  60,
+	eggSizeHuge:   240,
+}
+
+// eggSizes is the egg size of the pals of the original release that hatch from large or
+// huge eggs, used for pals the scraped paldex has no egg or rarity for. Pals not listed
+// hatch from normal eggs.
+var eggSizes = map[string]string{
+	"Jetragon": eggSizeHuge, "Frostallion": eggSizeHuge, "Frostallion Noct": eggSizeHuge, "Paladius": eggSizeHuge,
+	"Necromus": eggSizeHuge, "Blazamut": eggSizeHuge, "Suzaku": eggSizeHuge, "Suzaku Aqua": eggSizeHuge,
+	"Shadowbeak": eggSizeHuge, "Orserk": eggSizeHuge, "Astegon": eggSizeHuge, "Jormuntide": eggSizeHuge,
+	"Jormuntide Ignis": eggSizeHuge, "Relaxaurus": eggSizeHuge, "Relaxaurus Lux": eggSizeHuge, "Lyleen": eggSizeHuge,
+	"Lyleen Noct": eggSizeHuge, "Faleris": eggSizeHuge, "Grizzbolt": eggSizeHuge, "Anubis": eggSizeHuge,
+	"Mammorest": eggSizeHuge, "Mammorest Cryst": eggSizeHuge, "Menasting": eggSizeHuge, "Helzephyr": eggSizeHuge,
+	"Cryolinx": eggSizeHuge, "Quivern": eggSizeHuge, "Warsect": eggSizeHuge, "Beakon": eggSizeHuge,
+	"Penking": eggSizeLarge, "Vanwyrm": eggSizeLarge, "Vanwyrm Cryst": eggSizeLarge, "Chillet": eggSizeLarge,
+	"Univolt": eggSizeLarge, "Kitsun": eggSizeLarge, "Incineram": eggSizeLarge, "Incineram Noct": eggSizeLarge,
+	"Nitewing": eggSizeLarge, "Mossanda": eggSizeLarge, "Mossanda Lux": eggSizeLarge, "Ragnahawk": eggSizeLarge,
+	"Elphidran": eggSizeLarge, "Elphidran Aqua": eggSizeLarge, "Petallia": eggSizeLarge, "Pyrin": eggSizeLarge,
+	"Pyrin Noct": eggSizeLarge, "Reptyro": eggSizeLarge, "Reptyro Cryst": eggSizeLarge, "Blazehowl": eggSizeLarge,
+	"Blazehowl Noct": eggSizeLarge, "Katress": eggSizeLarge, "Sibelyx": eggSizeLarge, "Azurobe": eggSizeLarge,
+	"Surfent": eggSizeLarge, "Surfent Terra": eggSizeLarge, "Dinossom": eggSizeLarge, "Dinossom Lux": eggSizeLarge,
+	"Rayhound": eggSizeLarge, "Foxcicle": eggSizeLarge, "Kingpaca": eggSizeLarge, "Kingpaca Cryst": eggSizeLarge,
+	"Wumpo": eggSizeLarge, "Wumpo Botan": eggSizeLarge, "Grintale": eggSizeLarge, "Cinnamoth": eggSizeLarge,
+	"Bushi": eggSizeLarge, "Elizabee": eggSizeLarge, "Sweepa": eggSizeLarge, "Broncherry": eggSizeLarge,
+	"Broncherry Aqua": eggSizeLarge, "Fenglope": eggSizeLarge, "Digtoise": eggSizeLarge, "Tombat": eggSizeLarge,
+}
+
+// eggSize reads the size from the scraped egg name, falling back to rarity
+// when the egg wasn't scraped and to eggSizes when neither was
+func eggSize(pal *models.Pal) string {
+	egg := strings.ToLower(pal.Egg)
+	switch {
+	case strings.HasPrefix(egg, "huge"):
+		return eggSizeHuge
+	case strings.HasPrefix(egg, "large"):
+		return eggSizeLarge
+	case egg != "":
+		return eggSizeNormal
+	case pal.Rarity >= 8:
+		return eggSizeHuge
+	case pal.Rarity >= 5:
+		return eggSizeLarge
+	case pal.Rarity > 0:
+		return eggSizeNormal
+	}
+	if size, ok := eggSizes[pal.Name]; ok {
+		return size
+	}
+	return eggSizeNormal
+}
+
+// eggsForGenders is the expected eggs to hatch a pal in every needed gender.
+// With both genders needed the first egg has either one, then the other is still needed.
+func eggsForGenders(genders genderSet) float64 {
+	switch {
+	case genders.male && genders.female:
+		return 1 + 1/genderChance
+	case genders.male || genders.female:
+		return 1 / genderChance
+	}
+	return 1
+}
+
+// estimateEggs returns the cakes consumed and the minutes spent incubating to hatch
+// the expected number of eggs of the given species
+func (d *breedingData) estimateEggs(species string, expectedEggs float64) (float64, float64) {
+	minutes := incubationMinutes[eggSizeNormal]
+	if pal := d.findPal(species); pal != nil {
+		minutes = incubationMinutes[eggSize(pal)]
+	}
+	return expectedEggs * cakesPerEgg, expectedEggs * minutes
+}
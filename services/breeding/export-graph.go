@@ -0,0 +1,192 @@
+package breeding
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	GraphFormatDot     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatGraphML = "graphml"
+
+	DefaultGraphDepth = 2
+)
+
+var ErrUnknownGraphFormat = errors.New("unknown graph format")
+
+// graphEdge points from a parent to the child it produces, labelled with the full pair
+type graphEdge struct {
+	from  string
+	to    string
+	label string
+}
+
+// ExportGraph renders the scraped breeding data as a directed graph in the given format.
+// When root is set only the ancestors of root up to depth generations back are included.
+func (s *Service) ExportGraph(format string, root string, depth int) (string, error) {
+	format = strings.ToLower(format)
+	if format != GraphFormatDot && format != GraphFormatMermaid && format != GraphFormatGraphML {
+		return "", ErrUnknownGraphFormat
+	}
+
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return "", err
+	}
+
+	pairs := make([]breedingPair, 0, len(data.pairs))
+	for _, pair := range data.pairs {
+		pairs = append(pairs, pair)
+	}
+
+	if root != "" {
+		rootPal := data.findPal(root)
+		if rootPal == nil {
+			return "", ErrPalNotFound
+		}
+		if depth <= 0 {
+			depth = DefaultGraphDepth
+		}
+		pairs = ancestorPairs(pairs, rootPal.Name, depth)
+	}
+
+	nodes, edges := buildGraph(pairs)
+
+	switch format {
+	case GraphFormatMermaid:
+		return renderMermaid(nodes, edges), nil
+	case GraphFormatGraphML:
+		return renderGraphML(nodes, edges), nil
+	default:
+		return renderDot(nodes, edges), nil
+	}
+}
+
+// ancestorPairs keeps the pairs that lead to root within depth generations
+func ancestorPairs(pairs []breedingPair, root string, depth int) []breedingPair {
+	byChild := make(map[string][]breedingPair)
+	for _, pair := range pairs {
+		key := strings.ToLower(pair.child)
+		byChild[key] = append(byChild[key], pair)
+	}
+
+	result := make([]breedingPair, 0)
+	visited := map[string]bool{strings.ToLower(root): true}
+	current := []string{root}
+	for generation := 0; generation < depth && len(current) > 0; generation++ {
+		next := make([]string, 0)
+		for _, name := range current {
+			for _, pair := range byChild[strings.ToLower(name)] {
+				result = append(result, pair)
+				for _, parent := range []string{pair.parentA, pair.parentB} {
+					if !visited[strings.ToLower(parent)] {
+						visited[strings.ToLower(parent)] = true
+						next = append(next, parent)
+					}
+				}
+			}
+		}
+		current = next
+	}
+
+	return result
+}
+
+// buildGraph turns pairs into sorted nodes and edges so the output is stable
+func buildGraph(pairs []breedingPair) ([]string, []graphEdge) {
+	nodeSet := make(map[string]bool)
+	edgeSet := make(map[graphEdge]bool)
+	for _, pair := range pairs {
+		label := pair.parentA + " + " + pair.parentB
+		for _, parent := range []string{pair.parentA, pair.parentB} {
+			nodeSet[parent] = true
+			edgeSet[graphEdge{from: parent, to: pair.child, label: label}] = true
+		}
+		nodeSet[pair.child] = true
+	}
+
+	nodes := make([]string, 0, len(nodeSet))
+	for node := range nodeSet {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	edges := make([]graphEdge, 0, len(edgeSet))
+	for edge := range edgeSet {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		if edges[i].to != edges[j].to {
+			return edges[i].to < edges[j].to
+		}
+		return edges[i].label < edges[j].label
+	})
+
+	return nodes, edges
+}
+
+func renderDot(nodes []string, edges []graphEdge) string {
+	var out strings.Builder
+	out.WriteString("digraph breeding {\n")
+	for _, node := range nodes {
+		fmt.Fprintf(&out, "  %q;\n", node)
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&out, "  %q -> %q [label=%q];\n", edge.from, edge.to, edge.label)
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func renderMermaid(nodes []string, edges []graphEdge) string {
+	ids := make(map[string]string)
+	var out strings.Builder
+	out.WriteString("graph LR\n")
+	for i, node := range nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&out, "  %s[\"%s\"]\n", ids[node], mermaidEscape(node))
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&out, "  %s -->|\"%s\"| %s\n", ids[edge.from], mermaidEscape(edge.label), ids[edge.to])
+	}
+	return out.String()
+}
+
+func mermaidEscape(text string) string {
+	return strings.ReplaceAll(text, "\"", "#quot;")
+}
+
+func renderGraphML(nodes []string, edges []graphEdge) string {
+	ids := make(map[string]string)
+	var out strings.Builder
+	out.WriteString(xml.Header)
+	out.WriteString("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	out.WriteString("  <key id=\"name\" for=\"node\" attr.name=\"name\" attr.type=\"string\"/>\n")
+	out.WriteString("  <key id=\"label\" for=\"edge\" attr.name=\"label\" attr.type=\"string\"/>\n")
+	out.WriteString("  <graph id=\"breeding\" edgedefault=\"directed\">\n")
+	for i, node := range nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&out, "    <node id=\"%s\"><data key=\"name\">%s</data></node>\n", ids[node], xmlEscape(node))
+	}
+	for i, edge := range edges {
+		fmt.Fprintf(&out, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\"><data key=\"label\">%s</data></edge>\n",
+			i, ids[edge.from], ids[edge.to], xmlEscape(edge.label))
+	}
+	out.WriteString("  </graph>\n")
+	out.WriteString("</graphml>\n")
+	return out.String()
+}
+
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,107 @@
+package breeding
+
+import (
+	"encoding/xml"
+	"errors"
+	"palworld_tools/models"
+	"strings"
+	"testing"
+)
+
+func TestExportGraph(t *testing.T) {
+	pals := []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+		{Name: "B"},
+		{Name: "C", Children: []models.Child{{Parent: "D & \"E\"", Child: "F"}}},
+		{Name: "D & \"E\""},
+		{Name: "F"},
+	}
+	service, _ := newTestService(t, pals, nil)
+
+	cases := []struct {
+		name   string
+		format string
+		root   string
+		depth  int
+		want   string
+		err    error
+	}{
+		{"dot", GraphFormatDot, "", 0, `digraph breeding {
+  "A";
+  "B";
+  "C";
+  "D & \"E\"";
+  "F";
+  "A" -> "C" [label="A + B"];
+  "B" -> "C" [label="A + B"];
+  "C" -> "F" [label="C + D & \"E\""];
+  "D & \"E\"" -> "F" [label="C + D & \"E\""];
+}
+`, nil},
+		{"mermaid one generation", "MERMAID", "f", 1, `graph LR
+  n0["C"]
+  n1["D & #quot;E#quot;"]
+  n2["F"]
+  n0 -->|"C + D & #quot;E#quot;"| n2
+  n1 -->|"C + D & #quot;E#quot;"| n2
+`, nil},
+		{"mermaid default depth", GraphFormatMermaid, "F", 0, `graph LR
+  n0["A"]
+  n1["B"]
+  n2["C"]
+  n3["D & #quot;E#quot;"]
+  n4["F"]
+  n0 -->|"A + B"| n2
+  n1 -->|"A + B"| n2
+  n2 -->|"C + D & #quot;E#quot;"| n4
+  n3 -->|"C + D & #quot;E#quot;"| n4
+`, nil},
+		{"unknown format", "png", "", 0, "", ErrUnknownGraphFormat},
+		{"unknown root", GraphFormatDot, "Unknown", 1, "", ErrPalNotFound},
+	}
+	for _, c := range cases {
+		graph, err := service.ExportGraph(c.format, c.root, c.depth)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: got error %v, want %v", c.name, err, c.err)
+			continue
+		}
+		if graph != c.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", c.name, graph, c.want)
+		}
+	}
+}
+
+func TestExportGraphMLIsValidXML(t *testing.T) {
+	pals := []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B & <C>", Child: "D"}}},
+		{Name: "B & <C>"},
+		{Name: "D"},
+	}
+	service, _ := newTestService(t, pals, nil)
+
+	graph, err := service.ExportGraph(GraphFormatGraphML, "", 0)
+	if err != nil {
+		t.Fatalf("ExportGraph: %v", err)
+	}
+
+	var parsed struct {
+		Nodes []struct {
+			ID   string `xml:"id,attr"`
+			Name string `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+			Label  string `xml:"data"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.NewDecoder(strings.NewReader(graph)).Decode(&parsed); err != nil {
+		t.Fatalf("GraphML doesn't parse: %v\n%s", err, graph)
+	}
+	if len(parsed.Nodes) != 3 || parsed.Nodes[1].Name != "B & <C>" {
+		t.Errorf("got nodes %+v, want A, B & <C> and D", parsed.Nodes)
+	}
+	if len(parsed.Edges) != 2 || parsed.Edges[1].Source != parsed.Nodes[1].ID || parsed.Edges[1].Label != "A + B & <C>" {
+		t.Errorf("got edges %+v, want A and B & <C> pointing at D", parsed.Edges)
+	}
+}
@@ -0,0 +1,53 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/dto"
+)
+
+var (
+	ErrPalNotFound  = errors.New("pal name not found")
+	ErrPairNotFound = errors.New("breeding pair not found")
+)
+
+// FindChild returns the species hatched from breeding parentA with parentB.
+// Parent names are matched ignoring case, like models.FindPal. Pairs missing
+// from the scraped tables are computed from breeding power.
+func (s *Service) FindChild(parentA string, parentB string) (*dto.BreedingChild, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	palA := data.findPal(parentA)
+	palB := data.findPal(parentB)
+	if palA == nil || palB == nil {
+		return nil, ErrPalNotFound
+	}
+
+	childName, source := data.resolveChild(palA.Name, palB.Name)
+	if source == "" {
+		return nil, ErrPairNotFound
+	}
+
+	child := &dto.BreedingChild{
+		ParentA:     palA.Name,
+		ParentB:     palB.Name,
+		Name:        childName,
+		Source:      source,
+		Suitability: make([]dto.Suitability, 0),
+	}
+
+	// Scraped children may not have a paldex entry of their own
+	if childPal := data.findPal(childName); childPal != nil {
+		child.ImageUrl = childPal.ImageUrl
+		for _, suitability := range childPal.Suitability {
+			child.Suitability = append(child.Suitability, dto.Suitability{
+				Work:  suitability.Work,
+				Level: suitability.Level,
+			})
+		}
+	}
+
+	return child, nil
+}
@@ -0,0 +1,55 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"reflect"
+	"testing"
+)
+
+func TestFindChild(t *testing.T) {
+	pals := []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+		{Name: "B"},
+		{Name: "C", ImageUrl: "c.png", Suitability: []models.Suitability{{Work: "Kindling", Level: 2}}},
+		{Name: "Low", CombiRank: 100},
+		{Name: "Middle", CombiRank: 210},
+		{Name: "High", CombiRank: 300},
+	}
+	service, _ := newTestService(t, pals, nil)
+
+	cases := []struct {
+		parentA string
+		parentB string
+		want    *dto.BreedingChild
+		err     error
+	}{
+		{"A", "B", &dto.BreedingChild{
+			ParentA: "A", ParentB: "B", Name: "C", Source: sourceScraped,
+			ImageUrl: "c.png", Suitability: []dto.Suitability{{Work: "Kindling", Level: 2}},
+		}, nil},
+		{"b", "a", &dto.BreedingChild{
+			ParentA: "B", ParentB: "A", Name: "C", Source: sourceScraped,
+			ImageUrl: "c.png", Suitability: []dto.Suitability{{Work: "Kindling", Level: 2}},
+		}, nil},
+		{"B", "B", &dto.BreedingChild{
+			ParentA: "B", ParentB: "B", Name: "B", Source: sourceSameSpecies, Suitability: []dto.Suitability{},
+		}, nil},
+		{"Low", "High", &dto.BreedingChild{
+			ParentA: "Low", ParentB: "High", Name: "Middle", Source: sourceBreedingPower, Suitability: []dto.Suitability{},
+		}, nil},
+		{"A", "Unknown", nil, ErrPalNotFound},
+		{"A", "C", nil, ErrPairNotFound},
+	}
+	for _, c := range cases {
+		child, err := service.FindChild(c.parentA, c.parentB)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s + %s: got error %v, want %v", c.parentA, c.parentB, err, c.err)
+			continue
+		}
+		if !reflect.DeepEqual(child, c.want) {
+			t.Errorf("%s + %s: got %+v, want %+v", c.parentA, c.parentB, child, c.want)
+		}
+	}
+}
@@ -1 +1,179 @@
 package breeding
+
+import (
+	"errors"
+	"fmt"
+	"palworld_tools/dto"
+	"sort"
+	"strings"
+)
+
+var ErrNoChain = errors.New("no breeding chain found")
+
+// genderSet records which genders of a species are available for breeding
+type genderSet struct {
+	male   bool
+	female bool
+}
+
+// canPair reports whether a parent of species a can be bred with one of species b
+func canPair(a genderSet, b genderSet, sameSpecies bool) bool {
+	if sameSpecies {
+		return a.male && a.female
+	}
+	return (a.male && b.female) || (a.female && b.male)
+}
+
+// FindParentChain returns the shortest chain of breeding steps that produces
+// the target species, starting from the species already in the store of profile
+func (s *Service) FindParentChain(profile string, target string) ([]dto.BreedingStep, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	targetPal := data.findPal(target)
+	if targetPal == nil {
+		return nil, ErrPalNotFound
+	}
+
+	owned, err := s.readOwnedGenders(profile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Species-level search treats every owned species as available in both genders
+	for name := range owned {
+		owned[name] = genderSet{male: true, female: true}
+	}
+
+	return findChain(data, owned, targetPal.Name)
+}
+
+// readOwnedGenders returns the genders owned for each stored species, keyed by paldex name
+func (s *Service) readOwnedGenders(profile string, data *breedingData) (map[string]genderSet, error) {
+	palStore, err := s.dataService.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]genderSet)
+	for _, species := range palStore {
+		name := data.canonicalName(species.Name)
+		for _, pal := range species.StoredPals {
+			genders := owned[name]
+			switch strings.ToLower(pal.Gender) {
+			case "m":
+				genders.male = true
+			case "f":
+				genders.female = true
+			}
+			owned[name] = genders
+		}
+	}
+
+	return owned, nil
+}
+
+// findChain returns the chain with the fewest breeding steps that produces the
+// target, in breeding order. Every species keeps the smallest set of steps known to
+// produce it, and each pairable pair offers the union of its parents' sets plus one
+// step for the child, until no set gets smaller. Steps shared by both parents are
+// only counted once. Species bred along the way are assumed to be available in both genders.
+func findChain(data *breedingData, owned map[string]genderSet, target string) ([]dto.BreedingStep, error) {
+	// available maps lower-case species to its paldex name and genders
+	names := make(map[string]string)
+	available := make(map[string]genderSet)
+	isOwned := make(map[string]bool)
+	// steps maps each reachable species to the steps that produce it, keyed by child
+	steps := make(map[string]map[string]dto.BreedingStep)
+	for name, genders := range owned {
+		if !genders.male && !genders.female {
+			continue
+		}
+		key := strings.ToLower(name)
+		names[key] = name
+		available[key] = genders
+		isOwned[key] = true
+		steps[key] = map[string]dto.BreedingStep{}
+	}
+
+	for improved := true; improved; {
+		improved = false
+
+		parents := make([]string, 0, len(names))
+		for _, name := range names {
+			parents = append(parents, name)
+		}
+		sort.Strings(parents)
+
+		for i, parentA := range parents {
+			for j, parentB := range parents[i:] {
+				keyA := strings.ToLower(parentA)
+				keyB := strings.ToLower(parentB)
+				if !canPair(available[keyA], available[keyB], j == 0) {
+					continue
+				}
+				child, ok := data.child(parentA, parentB)
+				if !ok {
+					continue
+				}
+				childKey := strings.ToLower(child)
+
+				// Owned species need no steps, and parents that are bred from the child can't produce it
+				if isOwned[childKey] {
+					continue
+				}
+				if _, ok := steps[keyA][childKey]; ok {
+					continue
+				}
+				if _, ok := steps[keyB][childKey]; ok {
+					continue
+				}
+
+				candidate := make(map[string]dto.BreedingStep, len(steps[keyA])+len(steps[keyB])+1)
+				for key, step := range steps[keyA] {
+					candidate[key] = step
+				}
+				for key, step := range steps[keyB] {
+					candidate[key] = step
+				}
+				candidate[childKey] = dto.BreedingStep{ParentA: parentA, ParentB: parentB, Child: child}
+
+				if current, reached := steps[childKey]; reached && len(current) <= len(candidate) {
+					continue
+				}
+				if names[childKey] == "" {
+					names[childKey] = child
+					available[childKey] = genderSet{male: true, female: true}
+				}
+				steps[childKey] = candidate
+				improved = true
+			}
+		}
+	}
+
+	targetKey := strings.ToLower(target)
+	origin, reached := steps[targetKey]
+	if !reached {
+		return nil, fmt.Errorf("%w for %s", ErrNoChain, target)
+	}
+
+	// Walk back from the target so each step comes after the steps for its parents
+	chain := make([]dto.BreedingStep, 0, len(origin))
+	visited := make(map[string]bool)
+	var visit func(key string)
+	visit = func(key string) {
+		step, bred := origin[key]
+		if !bred || visited[key] {
+			return
+		}
+		visited[key] = true
+		visit(strings.ToLower(step.ParentA))
+		visit(strings.ToLower(step.ParentB))
+		chain = append(chain, step)
+	}
+	visit(targetKey)
+
+	return chain, nil
+}
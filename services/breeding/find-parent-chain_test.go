@@ -0,0 +1,49 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"reflect"
+	"testing"
+)
+
+func TestFindChainUsesFewestSteps(t *testing.T) {
+	// A+B and A+E both take one step, but C+E reaches T without breeding D
+	data := buildBreedingData([]models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}, {Parent: "E", Child: "D"}}},
+		{Name: "B"},
+		{Name: "C", Children: []models.Child{{Parent: "D", Child: "T"}, {Parent: "E", Child: "T"}}},
+		{Name: "D"},
+		{Name: "E"},
+		{Name: "T"},
+	})
+	both := genderSet{male: true, female: true}
+	owned := map[string]genderSet{"A": both, "B": both, "E": both}
+
+	chain, err := findChain(data, owned, "T")
+	if err != nil {
+		t.Fatalf("findChain: %v", err)
+	}
+	want := []dto.BreedingStep{
+		{ParentA: "A", ParentB: "B", Child: "C"},
+		{ParentA: "C", ParentB: "E", Child: "T"},
+	}
+	if !reflect.DeepEqual(chain, want) {
+		t.Errorf("got %+v, want %+v", chain, want)
+	}
+}
+
+func TestFindChainWithoutPath(t *testing.T) {
+	data := buildBreedingData([]models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+		{Name: "B"},
+		{Name: "C"},
+		{Name: "T"},
+	})
+	owned := map[string]genderSet{"A": {male: true}, "B": {female: true}}
+
+	if _, err := findChain(data, owned, "T"); !errors.Is(err, ErrNoChain) {
+		t.Errorf("got %v, want ErrNoChain", err)
+	}
+}
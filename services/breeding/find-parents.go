@@ -0,0 +1,54 @@
+package breeding
+
+import (
+	"palworld_tools/dto"
+	"sort"
+	"strings"
+)
+
+// FindParents returns every parent pair that produces the given species.
+// A+B and B+A are reported once, and pairs whose parents are both in the store of profile are flagged.
+func (s *Service) FindParents(profile string, species string) ([]dto.ParentPair, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	childPal := data.findPal(species)
+	if childPal == nil {
+		return nil, ErrPalNotFound
+	}
+
+	owned, err := s.readOwnedGenders(profile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make([]dto.ParentPair, 0)
+	for _, pair := range data.pairs {
+		if !strings.EqualFold(pair.child, childPal.Name) {
+			continue
+		}
+
+		_, ownA := owned[pair.parentA]
+		_, ownB := owned[pair.parentB]
+		parents = append(parents, dto.ParentPair{
+			ParentA: pair.parentA,
+			ParentB: pair.parentB,
+			Owned:   ownA && ownB,
+		})
+	}
+
+	// Owned pairs first, then alphabetical so the response is stable
+	sort.Slice(parents, func(i, j int) bool {
+		if parents[i].Owned != parents[j].Owned {
+			return parents[i].Owned
+		}
+		if parents[i].ParentA != parents[j].ParentA {
+			return parents[i].ParentA < parents[j].ParentA
+		}
+		return parents[i].ParentB < parents[j].ParentB
+	})
+
+	return parents, nil
+}
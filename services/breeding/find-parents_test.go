@@ -0,0 +1,51 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"reflect"
+	"testing"
+)
+
+// parentsTestPals breed C from A+B, listed on both parents, and from A+D
+var parentsTestPals = []models.Pal{
+	{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}, {Parent: "D", Child: "C"}}},
+	{Name: "B", Children: []models.Child{{Parent: "A", Child: "C"}}},
+	{Name: "C"},
+	{Name: "D"},
+}
+
+func TestFindParents(t *testing.T) {
+	service, dataService := newTestService(t, parentsTestPals, nil)
+	addTestSpecies(t, dataService, "A", models.StoredPal{Gender: "m"})
+	addTestSpecies(t, dataService, "B", models.StoredPal{Gender: "f"})
+
+	cases := []struct {
+		species string
+		want    []dto.ParentPair
+		err     error
+	}{
+		{"C", []dto.ParentPair{
+			{ParentA: "A", ParentB: "B", Owned: true},
+			{ParentA: "A", ParentB: "D", Owned: false},
+		}, nil},
+		{"c", []dto.ParentPair{
+			{ParentA: "A", ParentB: "B", Owned: true},
+			{ParentA: "A", ParentB: "D", Owned: false},
+		}, nil},
+		{"D", []dto.ParentPair{}, nil},
+		{"Unknown", nil, ErrPalNotFound},
+	}
+	for _, c := range cases {
+		parents, err := service.FindParents(datamanage.DefaultProfile, c.species)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: got error %v, want %v", c.species, err, c.err)
+			continue
+		}
+		if c.err == nil && !reflect.DeepEqual(parents, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.species, parents, c.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package breeding
+
+import (
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"strings"
+)
+
+// PlanBreeding returns a breeding plan for the target species that pairs one male
+// and one female at every step, using the concrete pals in the store of profile.
+// When the store can't supply a pairing, the plan falls back to the species-level
+// chain and lists the pals that still need to be caught.
+func (s *Service) PlanBreeding(profile string, target string) (*dto.BreedingPlan, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	targetPal := data.findPal(target)
+	if targetPal == nil {
+		return nil, ErrPalNotFound
+	}
+
+	palStore, err := s.dataService.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+	storedPals := storedPalsByGender(data, palStore)
+
+	owned, err := s.readOwnedGenders(profile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := findChain(data, owned, targetPal.Name)
+	if err != nil {
+		// Retry ignoring genders so we can tell which pals are missing
+		anyGender := make(map[string]genderSet)
+		for name := range owned {
+			anyGender[name] = genderSet{male: true, female: true}
+		}
+		chain, err = findChain(data, anyGender, targetPal.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &dto.BreedingPlan{
+		Target:      targetPal.Name,
+		Steps:       make([]dto.PlannedBreedingStep, 0, len(chain)),
+		MissingPals: make([]dto.MissingPal, 0),
+	}
+
+	bred := make(map[string]bool)
+	missing := make(map[dto.MissingPal]bool)
+	// needed collects the genders each bred species has to hatch in for later steps
+	needed := make(map[string]genderSet)
+	for _, step := range chain {
+		planned, stepMissing := assignParents(step, storedPals, bred)
+		for _, parent := range []dto.BreedingParent{planned.ParentA, planned.ParentB} {
+			if !parent.Bred {
+				continue
+			}
+			genders := needed[strings.ToLower(parent.Species)]
+			if parent.Gender == "m" {
+				genders.male = true
+			} else {
+				genders.female = true
+			}
+			needed[strings.ToLower(parent.Species)] = genders
+		}
+
+		plan.Steps = append(plan.Steps, planned)
+		for _, pal := range stepMissing {
+			if !missing[pal] {
+				missing[pal] = true
+				plan.MissingPals = append(plan.MissingPals, pal)
+			}
+		}
+		bred[strings.ToLower(step.Child)] = true
+	}
+
+	// The child species is fixed, but a child used as a parent has to hatch in that gender
+	for i := range plan.Steps {
+		planned := &plan.Steps[i]
+		planned.ExpectedEggs = eggsForGenders(needed[strings.ToLower(planned.Child)])
+		planned.Cakes, planned.IncubationMinutes = data.estimateEggs(planned.Child, planned.ExpectedEggs)
+		plan.TotalExpectedEggs += planned.ExpectedEggs
+		plan.TotalCakes += planned.Cakes
+		plan.TotalIncubationMinutes += planned.IncubationMinutes
+	}
+
+	return plan, nil
+}
+
+// storedPalsByGender indexes the store by lower-case species and gender
+func storedPalsByGender(data *breedingData, palStore []models.PalSpecies) map[string]map[string][]models.StoredPal {
+	result := make(map[string]map[string][]models.StoredPal)
+	for _, species := range palStore {
+		key := strings.ToLower(data.canonicalName(species.Name))
+		if result[key] == nil {
+			result[key] = make(map[string][]models.StoredPal)
+		}
+		for _, pal := range species.StoredPals {
+			gender := strings.ToLower(pal.Gender)
+			result[key][gender] = append(result[key][gender], pal)
+		}
+	}
+	return result
+}
+
+// assignParents picks a male and a female for the step, preferring stored pals.
+// Parents bred in an earlier step can be hatched in whichever gender is needed.
+func assignParents(step dto.BreedingStep, storedPals map[string]map[string][]models.StoredPal, bred map[string]bool) (dto.PlannedBreedingStep, []dto.MissingPal) {
+	var best dto.PlannedBreedingStep
+	var bestMissing []dto.MissingPal
+
+	for i, genders := range [][2]string{{"m", "f"}, {"f", "m"}} {
+		parentA, missingA := pickParent(step.ParentA, genders[0], storedPals, bred)
+		parentB, missingB := pickParent(step.ParentB, genders[1], storedPals, bred)
+
+		stepMissing := make([]dto.MissingPal, 0)
+		if missingA != nil {
+			stepMissing = append(stepMissing, *missingA)
+		}
+		if missingB != nil {
+			stepMissing = append(stepMissing, *missingB)
+		}
+
+		if i == 0 || len(stepMissing) < len(bestMissing) {
+			best = dto.PlannedBreedingStep{ParentA: parentA, ParentB: parentB, Child: step.Child}
+			bestMissing = stepMissing
+		}
+	}
+
+	return best, bestMissing
+}
+
+func pickParent(species string, gender string, storedPals map[string]map[string][]models.StoredPal, bred map[string]bool) (dto.BreedingParent, *dto.MissingPal) {
+	parent := dto.BreedingParent{Species: species, Gender: gender}
+
+	key := strings.ToLower(species)
+	if pals := storedPals[key][gender]; len(pals) > 0 {
+		parent.PalId = pals[0].ID
+		return parent, nil
+	}
+	if bred[key] {
+		parent.Bred = true
+		return parent, nil
+	}
+
+	return parent, &dto.MissingPal{Species: species, Gender: gender}
+}
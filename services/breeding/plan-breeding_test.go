@@ -0,0 +1,42 @@
+package breeding
+
+import (
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"testing"
+)
+
+func TestPlanBreedingCountsEggsForNeededGenders(t *testing.T) {
+	service, dataService := newTestService(t, []models.Pal{
+		{Name: "Lamball", Children: []models.Child{{Parent: "Cattiva", Child: "Bushi"}, {Parent: "Bushi", Child: "Anubis"}}},
+		{Name: "Cattiva"},
+		{Name: "Bushi"},
+		{Name: "Anubis"},
+	}, nil)
+	for _, pal := range []struct{ species, gender string }{{"Lamball", "m"}, {"Cattiva", "f"}} {
+		if _, err := dataService.AddPal(datamanage.DefaultProfile, pal.species, models.StoredPal{Gender: pal.gender}, datamanage.SourceAPI); err != nil {
+			t.Fatalf("AddPal: %v", err)
+		}
+	}
+
+	plan, err := service.PlanBreeding(datamanage.DefaultProfile, "Anubis")
+	if err != nil {
+		t.Fatalf("PlanBreeding: %v", err)
+	}
+	if len(plan.Steps) != 2 {
+		t.Fatalf("got %+v, want Bushi then Anubis", plan.Steps)
+	}
+
+	// Bushi has to hatch female to pair with the male Lamball, the target can be either
+	bushi, anubis := plan.Steps[0], plan.Steps[1]
+	if bushi.ExpectedEggs != 2 || anubis.ExpectedEggs != 1 {
+		t.Errorf("got %v and %v expected eggs, want 2 and 1", bushi.ExpectedEggs, anubis.ExpectedEggs)
+	}
+	// Bushi hatches from a large egg and Anubis from a huge one
+	if bushi.IncubationMinutes != 120 || anubis.IncubationMinutes != 240 {
+		t.Errorf("got %v and %v incubation minutes, want 120 and 240", bushi.IncubationMinutes, anubis.IncubationMinutes)
+	}
+	if plan.TotalExpectedEggs != 3 || plan.TotalIncubationMinutes != 360 {
+		t.Errorf("got %v eggs and %v minutes in total, want 3 and 360", plan.TotalExpectedEggs, plan.TotalIncubationMinutes)
+	}
+}
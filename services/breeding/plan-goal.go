@@ -0,0 +1,404 @@
+package breeding
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"palworld_tools/dto"
+	"strings"
+)
+
+const maxRankedPlans = 5
+
+// maxGoalPairings caps how many parent pairs a goal search tries, which keeps
+// large stores and unreachable goals to a few seconds. Tests lower it.
+var maxGoalPairings = 200000
+
+var (
+	ErrNoGoalPlan          = errors.New("no breeding plan found")
+	ErrComboNotFound       = errors.New("passive skill combo not found")
+	ErrGoalPassivesMissing = errors.New("passive skills or combo is required")
+	ErrGoalSearchLimit     = errors.New("search limit reached")
+)
+
+// goalNode is one pal in a goal plan: either a stored pal or a child bred from two other nodes.
+// mask marks which of the goal passives the pal carries. A bred pal carries exactly the
+// passives in mask, a bred pal without goal passives is only bred for its species.
+// carried is what the pal can pass on: its own passives for a stored pal, and for a bred
+// pal without goal passives the other passives of its parents, as it inherits at least one.
+type goalNode struct {
+	species  string
+	mask     uint
+	passives []string
+	carried  []string
+	genders  genderSet
+	palId    string
+
+	parentA *goalNode
+	parentB *goalNode
+	chance  float64
+	eggs    float64
+	cost    float64
+}
+
+func (n *goalNode) key() string {
+	if n.palId != "" {
+		return fmt.Sprintf("%s|%d|pal:%s", strings.ToLower(n.species), n.mask, n.palId)
+	}
+	return fmt.Sprintf("%s|%d", strings.ToLower(n.species), n.mask)
+}
+
+// isGoal reports whether the node is the target species with every goal passive
+func (n *goalNode) isGoal(target string, fullMask uint) bool {
+	return strings.EqualFold(n.species, target) && n.mask == fullMask
+}
+
+// goalQueue orders candidate nodes by their total expected eggs
+type goalQueue []*goalNode
+
+func (q goalQueue) Len() int           { return len(q) }
+func (q goalQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q goalQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *goalQueue) Push(x any)        { *q = append(*q, x.(*goalNode)) }
+func (q *goalQueue) Pop() any {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// PlanGoal builds ranked multi-generation plans that end with the target species
+// carrying every goal passive. Each plan starts from the store of profile, chains
+// species and stacks passives across generations, and lists the expected eggs per step.
+// When passives is empty the passive skill combo with the given name is used instead.
+// The search stops when ctx is done or after maxGoalPairings parent pairs, returning the
+// plans found so far or ErrGoalSearchLimit if there are none.
+func (s *Service) PlanGoal(ctx context.Context, profile string, target string, passives []string, comboName string) (*dto.GoalPlan, error) {
+	data, err := s.loadBreedingData()
+	if err != nil {
+		return nil, err
+	}
+
+	targetPal := data.findPal(target)
+	if targetPal == nil {
+		return nil, ErrPalNotFound
+	}
+
+	goalPassives, err := s.readGoalPassives(passives, comboName)
+	if err != nil {
+		return nil, err
+	}
+
+	passiveSkills, err := s.dataService.PassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+
+	palStore, err := s.dataService.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	goalIndex := make(map[string]uint)
+	for i, skill := range goalPassives {
+		goalIndex[strings.ToLower(skill)] = 1 << i
+	}
+	fullMask := uint(1)<<len(goalPassives) - 1
+
+	// Every goal passive has to come from somewhere in the store
+	var carried uint
+	queue := &goalQueue{}
+	for _, species := range palStore {
+		for _, pal := range species.StoredPals {
+			node := &goalNode{
+				species:  data.canonicalName(species.Name),
+				passives: pal.PassiveSkills,
+				carried:  pal.PassiveSkills,
+				palId:    pal.ID,
+			}
+			switch strings.ToLower(pal.Gender) {
+			case "m":
+				node.genders.male = true
+			case "f":
+				node.genders.female = true
+			}
+			for _, skill := range pal.PassiveSkills {
+				node.mask |= goalIndex[strings.ToLower(skill)]
+			}
+			carried |= node.mask
+			heap.Push(queue, node)
+		}
+	}
+
+	missing := make([]string, 0)
+	for i, skill := range goalPassives {
+		if carried&(1<<i) == 0 {
+			missing = append(missing, skill)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w, no stored pal carries passive skills: %s", ErrNoGoalPlan, strings.Join(missing, ", "))
+	}
+
+	best := make(map[string]float64)
+	finalized := make(map[string]bool)
+	done := make([]*goalNode, 0)
+	goals := make([]*goalNode, 0, maxRankedPlans)
+	pairings := 0
+
+search:
+	for queue.Len() > 0 && len(goals) < maxRankedPlans {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		node := heap.Pop(queue).(*goalNode)
+		if node.isGoal(targetPal.Name, fullMask) {
+			// Goals come off the queue cheapest first and are never bred further.
+			// A stored goal pal is a plan without steps.
+			goals = append(goals, node)
+			continue
+		}
+		key := node.key()
+		if finalized[key] {
+			continue
+		}
+		finalized[key] = true
+		done = append(done, node)
+
+		for _, other := range done {
+			if !canBreedNodes(node, other) {
+				continue
+			}
+			if pairings++; pairings > maxGoalPairings {
+				break search
+			}
+			child, ok := data.child(node.species, other.species)
+			if !ok {
+				continue
+			}
+
+			pool := passivePool(node.carried, other.carried)
+			for _, mask := range []uint{node.mask | other.mask, 0} {
+				wanted := masked(goalPassives, mask)
+				carried := wanted
+				var chance float64
+				if mask != 0 {
+					chance = passiveChance(pool, wanted, len(passiveSkills))
+				} else {
+					chance = noPassiveChance(pool, goalPassives, len(passiveSkills))
+					carried = withoutPassives(pool, goalPassives)
+				}
+				if chance <= 0 {
+					continue
+				}
+
+				cost := parentCost(node, node == other) + 1/chance
+				if node != other {
+					cost += parentCost(other, false)
+				}
+
+				candidate := &goalNode{
+					species:  child,
+					mask:     mask,
+					passives: wanted,
+					carried:  carried,
+					genders:  genderSet{male: true, female: true},
+					parentA:  node,
+					parentB:  other,
+					chance:   chance,
+					eggs:     1 / chance,
+					cost:     cost,
+				}
+				if candidate.isGoal(targetPal.Name, fullMask) {
+					// Every way to breed the goal is a separate plan
+					heap.Push(queue, candidate)
+					continue
+				}
+				candidateKey := candidate.key()
+				if previous, seen := best[candidateKey]; seen && previous <= cost {
+					continue
+				}
+				best[candidateKey] = cost
+				heap.Push(queue, candidate)
+			}
+		}
+	}
+
+	if len(goals) == 0 {
+		if pairings > maxGoalPairings {
+			return nil, fmt.Errorf("%w before a breeding plan for %s was found, try fewer passive skills", ErrGoalSearchLimit, targetPal.Name)
+		}
+		return nil, fmt.Errorf("%w for %s", ErrNoGoalPlan, targetPal.Name)
+	}
+
+	plan := &dto.GoalPlan{
+		Target:        targetPal.Name,
+		PassiveSkills: goalPassives,
+		Plans:         make([]dto.RankedPlan, 0),
+	}
+	for _, goal := range goals {
+		ranked := dto.RankedPlan{Rank: len(plan.Plans) + 1, Steps: goalSteps(goal)}
+		for i := range ranked.Steps {
+			step := &ranked.Steps[i]
+			step.Cakes, step.IncubationMinutes = data.estimateEggs(step.Child, step.ExpectedEggs)
+			ranked.TotalExpectedEggs += step.ExpectedEggs
+			ranked.TotalCakes += step.Cakes
+			ranked.TotalIncubationMinutes += step.IncubationMinutes
+		}
+		plan.Plans = append(plan.Plans, ranked)
+	}
+
+	return plan, nil
+}
+
+// readGoalPassives validates the goal passives, falling back to a named combo
+func (s *Service) readGoalPassives(passives []string, comboName string) ([]string, error) {
+	if len(passives) == 0 && comboName != "" {
+		combo, err := s.dataService.FindPassiveSkillCombo(comboName)
+		if err != nil {
+			return nil, err
+		}
+		if combo == nil {
+			return nil, ErrComboNotFound
+		}
+		passives = combo.Skills
+	}
+	if len(passives) == 0 {
+		return nil, ErrGoalPassivesMissing
+	}
+
+	passiveSkills, err := s.dataService.PassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolvePassiveSkills(passiveSkills, passives)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved) > maxPassives {
+		return nil, ErrTooManyPassives
+	}
+
+	return resolved, nil
+}
+
+// canBreedNodes reports whether two plan nodes can be paired as a male and a female.
+// A bred node can be paired with itself by hatching it twice.
+func canBreedNodes(a *goalNode, b *goalNode) bool {
+	if a == b {
+		return a.palId == ""
+	}
+	return canPair(a.genders, b.genders, false)
+}
+
+func masked(skills []string, mask uint) []string {
+	result := make([]string, 0, len(skills))
+	for i, skill := range skills {
+		if mask&(1<<i) != 0 {
+			result = append(result, skill)
+		}
+	}
+	return result
+}
+
+// withoutPassives returns skills minus the removed ones
+func withoutPassives(skills []string, removed []string) []string {
+	remove := make(map[string]bool)
+	for _, skill := range removed {
+		remove[strings.ToLower(skill)] = true
+	}
+	result := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		if !remove[strings.ToLower(skill)] {
+			result = append(result, skill)
+		}
+	}
+	return result
+}
+
+// parentCost is the expected eggs for node when it is used as a parent, counting the
+// eggs needed to hatch the gender the pairing needs. A bred pal paired with itself
+// has to be hatched once in each gender.
+func parentCost(node *goalNode, both bool) float64 {
+	if node.palId != "" {
+		return node.cost
+	}
+	return node.cost - node.eggs + parentEggs(node, both)
+}
+
+// parentEggs is the expected eggs for the step that breeds node, when node is used as
+// one parent or, with both set, as both parents
+func parentEggs(node *goalNode, both bool) float64 {
+	return node.eggs * eggsForGenders(genderSet{male: true, female: both})
+}
+
+// goalSteps flattens the breeding tree under goal into steps in breeding order.
+// Steps that breed a parent also count the eggs needed to hatch its gender.
+func goalSteps(goal *goalNode) []dto.GoalPlanStep {
+	steps := make([]dto.GoalPlanStep, 0)
+	visited := make(map[*goalNode]bool)
+
+	var visit func(node *goalNode, parent bool, both bool)
+	visit = func(node *goalNode, parent bool, both bool) {
+		if node.parentA == nil || visited[node] {
+			return
+		}
+		visited[node] = true
+		selfPaired := node.parentA == node.parentB
+		visit(node.parentA, true, selfPaired)
+		visit(node.parentB, true, selfPaired)
+
+		parentA, parentB := goalParent(node.parentA), goalParent(node.parentB)
+		switch {
+		case parentA.Bred && parentB.Bred:
+			parentA.Gender, parentB.Gender = "m", "f"
+		case parentA.Bred:
+			parentA.Gender = oppositeGender(parentB.Gender)
+		case parentB.Bred:
+			parentB.Gender = oppositeGender(parentA.Gender)
+		}
+
+		probability, eggs := node.chance, node.eggs
+		if parent {
+			probability, eggs = node.chance*genderChance, parentEggs(node, both)
+		}
+		steps = append(steps, dto.GoalPlanStep{
+			ParentA:       parentA,
+			ParentB:       parentB,
+			Child:         node.species,
+			PassiveSkills: node.passives,
+			Probability:   probability,
+			ExpectedEggs:  eggs,
+		})
+	}
+	visit(goal, false, false)
+
+	return steps
+}
+
+func goalParent(node *goalNode) dto.BreedingParent {
+	parent := dto.BreedingParent{
+		Species:       node.species,
+		PassiveSkills: node.passives,
+	}
+	if node.palId == "" {
+		parent.Bred = true
+		return parent
+	}
+
+	parent.PalId = node.palId
+	if node.genders.male {
+		parent.Gender = "m"
+	} else {
+		parent.Gender = "f"
+	}
+	return parent
+}
+
+func oppositeGender(gender string) string {
+	if gender == "m" {
+		return "f"
+	}
+	return "m"
+}
@@ -0,0 +1,194 @@
+package breeding
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"testing"
+)
+
+var testPassiveSkills = []models.PassiveSkill{
+	{Name: "Artisan"}, {Name: "Serious"}, {Name: "Work Slave"}, {Name: "Lucky"},
+	{Name: "Legend"}, {Name: "Swift"}, {Name: "Nimble"}, {Name: "Runner"},
+}
+
+func TestPassiveChanceMatchesSimulation(t *testing.T) {
+	service, _ := newTestService(t, nil, testPassiveSkills)
+
+	cases := []struct {
+		parentA []string
+		parentB []string
+		desired []string
+	}{
+		{[]string{"Artisan", "Serious"}, []string{"Work Slave"}, []string{"Artisan", "Serious", "Work Slave"}},
+		{[]string{"Artisan", "Lucky"}, []string{"Swift", "Nimble"}, []string{"Artisan"}},
+		{nil, nil, []string{"Legend"}},
+		{[]string{"Artisan"}, nil, nil},
+	}
+	for _, c := range cases {
+		simulated, err := service.SimulatePassives(c.parentA, c.parentB, c.desired, 200000, 1)
+		if err != nil {
+			t.Fatalf("SimulatePassives: %v", err)
+		}
+		exact := passiveChance(passivePool(c.parentA, c.parentB), c.desired, len(testPassiveSkills))
+		if math.Abs(exact-simulated.Probability) > 0.004 {
+			t.Errorf("%v x %v -> %v: exact chance %.4f, simulated %.4f", c.parentA, c.parentB, c.desired, exact, simulated.Probability)
+		}
+	}
+}
+
+func TestPlanGoalAgreesWithSimulation(t *testing.T) {
+	service, dataService := newTestService(t, []models.Pal{{Name: "Lamball"}}, testPassiveSkills)
+	addTestPal(t, dataService, models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan", "Serious"}})
+	addTestPal(t, dataService, models.StoredPal{Gender: "f", PassiveSkills: []string{"Work Slave"}})
+
+	goal := []string{"Artisan", "Serious", "Work Slave"}
+	plan, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", goal, "")
+	if err != nil {
+		t.Fatalf("PlanGoal: %v", err)
+	}
+	best := plan.Plans[0]
+	if len(best.Steps) != 1 {
+		t.Fatalf("got %d steps in the best plan, want to breed the two stored pals", len(best.Steps))
+	}
+
+	simulated, err := service.SimulatePassives([]string{"Artisan", "Serious"}, []string{"Work Slave"}, goal, 200000, 1)
+	if err != nil {
+		t.Fatalf("SimulatePassives: %v", err)
+	}
+	if math.Abs(best.Steps[0].Probability-simulated.Probability) > 0.004 {
+		t.Errorf("planner chance %.4f, simulated %.4f", best.Steps[0].Probability, simulated.Probability)
+	}
+}
+
+func TestPlanGoalRanksSeveralPlans(t *testing.T) {
+	service, dataService := newTestService(t, []models.Pal{{Name: "Lamball"}}, testPassiveSkills)
+	addTestPal(t, dataService, models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan"}})
+	addTestPal(t, dataService, models.StoredPal{Gender: "f", PassiveSkills: []string{"Serious"}})
+	addTestPal(t, dataService, models.StoredPal{Gender: "f", PassiveSkills: []string{"Artisan", "Lucky"}})
+
+	plan, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", []string{"Artisan", "Serious"}, "")
+	if err != nil {
+		t.Fatalf("PlanGoal: %v", err)
+	}
+	if len(plan.Plans) != maxRankedPlans {
+		t.Fatalf("got %d plans, want %d", len(plan.Plans), maxRankedPlans)
+	}
+	for i := 1; i < len(plan.Plans); i++ {
+		if plan.Plans[i].TotalExpectedEggs < plan.Plans[i-1].TotalExpectedEggs {
+			t.Errorf("plan %d needs fewer eggs than plan %d", i+1, i)
+		}
+	}
+}
+
+func TestPlanGoalErrors(t *testing.T) {
+	service, dataService := newTestService(t, []models.Pal{{Name: "Lamball"}}, testPassiveSkills)
+	addTestPal(t, dataService, models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan"}})
+
+	if _, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Anubis", []string{"Artisan"}, ""); !errors.Is(err, ErrPalNotFound) {
+		t.Errorf("unknown target: got %v, want ErrPalNotFound", err)
+	}
+	if _, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", []string{"Legend"}, ""); !errors.Is(err, ErrNoGoalPlan) {
+		t.Errorf("passive nobody carries: got %v, want ErrNoGoalPlan", err)
+	}
+	if _, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", nil, "Unknown combo"); !errors.Is(err, ErrComboNotFound) {
+		t.Errorf("unknown combo: got %v, want ErrComboNotFound", err)
+	}
+	if _, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", nil, ""); !errors.Is(err, ErrGoalPassivesMissing) {
+		t.Errorf("no passives: got %v, want ErrGoalPassivesMissing", err)
+	}
+}
+
+func TestNoPassiveChanceMatchesRolls(t *testing.T) {
+	allSkills := make([]string, 0, len(testPassiveSkills))
+	for _, skill := range testPassiveSkills {
+		allSkills = append(allSkills, skill.Name)
+	}
+
+	cases := []struct {
+		pool    []string
+		avoided []string
+	}{
+		{[]string{"Artisan", "Lucky"}, []string{"Artisan"}},
+		{[]string{"Artisan", "Serious", "Swift"}, []string{"Artisan", "Serious"}},
+		{[]string{"Artisan"}, []string{"Artisan"}},
+		{nil, []string{"Legend"}},
+	}
+	for _, c := range cases {
+		rng := rand.New(rand.NewSource(1))
+		const trials = 200000
+		misses := 0
+		for i := 0; i < trials; i++ {
+			child := rollChildPassives(rng, c.pool, allSkills)
+			if len(withoutPassives(child, c.avoided)) == len(child) {
+				misses++
+			}
+		}
+		exact := noPassiveChance(c.pool, c.avoided, len(allSkills))
+		if math.Abs(exact-float64(misses)/trials) > 0.004 {
+			t.Errorf("%v avoiding %v: exact chance %.4f, rolled %.4f", c.pool, c.avoided, exact, float64(misses)/trials)
+		}
+	}
+}
+
+func TestPlanGoalNeverBreedsPassivesAway(t *testing.T) {
+	// Both parents only carry the goal passive, so C always inherits it and
+	// can't be bred without it for a cheaper species only step
+	pals := []models.Pal{
+		{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+		{Name: "B"},
+		{Name: "C", Children: []models.Child{{Parent: "A", Child: "T"}}},
+		{Name: "T"},
+	}
+	service, dataService := newTestService(t, pals, testPassiveSkills)
+	addTestSpecies(t, dataService, "A", models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan"}})
+	addTestSpecies(t, dataService, "B", models.StoredPal{Gender: "f", PassiveSkills: []string{"Artisan"}})
+	addTestSpecies(t, dataService, "A", models.StoredPal{Gender: "f", PassiveSkills: []string{"Artisan"}})
+
+	plan, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "T", []string{"Artisan"}, "")
+	if err != nil {
+		t.Fatalf("PlanGoal: %v", err)
+	}
+	for _, ranked := range plan.Plans {
+		for _, step := range ranked.Steps {
+			if len(step.PassiveSkills) == 0 {
+				t.Errorf("plan %d breeds %s without passive skills from parents that only carry Artisan", ranked.Rank, step.Child)
+			}
+		}
+	}
+}
+
+func TestPlanGoalStops(t *testing.T) {
+	service, dataService := newTestService(t, []models.Pal{{Name: "Lamball"}}, testPassiveSkills)
+	addTestPal(t, dataService, models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan"}})
+	addTestPal(t, dataService, models.StoredPal{Gender: "f", PassiveSkills: []string{"Serious"}})
+	goal := []string{"Artisan", "Serious"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.PlanGoal(ctx, datamanage.DefaultProfile, "Lamball", goal, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled request: got %v, want context.Canceled", err)
+	}
+
+	defer func(limit int) { maxGoalPairings = limit }(maxGoalPairings)
+	maxGoalPairings = 0
+	if _, err := service.PlanGoal(context.Background(), datamanage.DefaultProfile, "Lamball", goal, ""); !errors.Is(err, ErrGoalSearchLimit) {
+		t.Errorf("search over the limit: got %v, want ErrGoalSearchLimit", err)
+	}
+}
+
+func addTestPal(t *testing.T, dataService *datamanage.Service, pal models.StoredPal) {
+	t.Helper()
+	addTestSpecies(t, dataService, "Lamball", pal)
+}
+
+func addTestSpecies(t *testing.T, dataService *datamanage.Service, species string, pal models.StoredPal) {
+	t.Helper()
+
+	if _, err := dataService.AddPal(datamanage.DefaultProfile, species, pal, datamanage.SourceAPI); err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+}
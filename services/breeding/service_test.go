@@ -0,0 +1,38 @@
+package breeding
+
+import (
+	"palworld_tools/config"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"testing"
+)
+
+// newTestService returns a breeding service over a data directory in t.TempDir()
+// holding pals and the given passive skills
+func newTestService(t *testing.T, pals []models.Pal, passiveSkills []models.PassiveSkill) (*Service, *datamanage.Service) {
+	t.Helper()
+
+	dataService, err := datamanage.NewService(&config.Config{
+		DataDir:                t.TempDir(),
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if err := dataService.WritePaldex(pals); err != nil {
+		t.Fatalf("WritePaldex: %v", err)
+	}
+	if err := dataService.WritePassiveSkills(passiveSkills); err != nil {
+		t.Fatalf("WritePassiveSkills: %v", err)
+	}
+
+	service := NewService(dataService)
+	if err := service.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	return service, dataService
+}
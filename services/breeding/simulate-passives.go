@@ -0,0 +1,265 @@
+package breeding
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"strings"
+)
+
+const (
+	maxPassives = 4
+
+	DefaultSimulationTrials = 10000
+	DefaultSimulationSeed   = 1
+	MaxSimulationTrials     = 1000000
+)
+
+var (
+	ErrPassiveSkillNotFound = errors.New("passive skill not found")
+	ErrTooManyPassives      = fmt.Errorf("a pal can have at most %d passive skills", maxPassives)
+	ErrTooManyTrials        = fmt.Errorf("trials can be at most %d", MaxSimulationTrials)
+)
+
+// inheritWeights is the chance of the child inheriting 1, 2, 3 or 4 passives
+// from the combined pool of both parents
+var inheritWeights = []float64{0.4, 0.3, 0.2, 0.1}
+
+// randomWeights is the chance of 0, 1, 2 or 3 random passives being added on top
+// of the inherited ones, up to the four passive limit
+var randomWeights = []float64{0.4, 0.3, 0.2, 0.1}
+
+// genderChance is the chance of an egg hatching the gender a later step needs.
+// Every species is assumed to hatch males and females equally often.
+const genderChance = 0.5
+
+// SimulatePassives estimates the probability that a child of two parents ends up
+// with exactly the desired passives. The same seed always gives the same result.
+func (s *Service) SimulatePassives(parentA []string, parentB []string, desired []string, trials int, seed int64) (*dto.PassiveSimulation, error) {
+	if trials <= 0 {
+		trials = DefaultSimulationTrials
+	}
+	if trials > MaxSimulationTrials {
+		return nil, ErrTooManyTrials
+	}
+
+	passiveSkills, err := s.dataService.PassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+
+	var skills [3][]string
+	for i, names := range [][]string{parentA, parentB, desired} {
+		if skills[i], err = resolvePassiveSkills(passiveSkills, names); err != nil {
+			return nil, err
+		}
+		if len(skills[i]) > maxPassives {
+			return nil, ErrTooManyPassives
+		}
+	}
+	desiredSkills := skills[2]
+
+	pool := passivePool(skills[0], skills[1])
+	allSkills := make([]string, 0, len(passiveSkills))
+	for _, skill := range passiveSkills {
+		allSkills = append(allSkills, skill.Name)
+	}
+
+	want := make(map[string]bool)
+	for _, skill := range desiredSkills {
+		want[strings.ToLower(skill)] = true
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	matches := 0
+	for i := 0; i < trials; i++ {
+		child := rollChildPassives(rng, pool, allSkills)
+		if len(child) != len(want) {
+			continue
+		}
+		exact := true
+		for _, skill := range child {
+			if !want[strings.ToLower(skill)] {
+				exact = false
+				break
+			}
+		}
+		if exact {
+			matches++
+		}
+	}
+
+	result := &dto.PassiveSimulation{
+		Desired:     desiredSkills,
+		Trials:      trials,
+		Seed:        seed,
+		Matches:     matches,
+		Probability: float64(matches) / float64(trials),
+	}
+	if matches > 0 {
+		result.ExpectedEggs = float64(trials) / float64(matches)
+	}
+
+	return result, nil
+}
+
+// resolvePassiveSkills validates names against passive_skills.json and returns their canonical spelling
+func resolvePassiveSkills(passiveSkills []models.PassiveSkill, names []string) ([]string, error) {
+	resolved := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		skill := models.FindPassiveSkill(passiveSkills, name)
+		if skill == nil {
+			return nil, fmt.Errorf("%w: %s", ErrPassiveSkillNotFound, name)
+		}
+		if seen[strings.ToLower(skill.Name)] {
+			continue
+		}
+		seen[strings.ToLower(skill.Name)] = true
+		resolved = append(resolved, skill.Name)
+	}
+	return resolved, nil
+}
+
+// passivePool merges both parents' passives without duplicates
+func passivePool(parentA []string, parentB []string) []string {
+	pool := make([]string, 0, len(parentA)+len(parentB))
+	seen := make(map[string]bool)
+	for _, skill := range append(append([]string{}, parentA...), parentB...) {
+		if seen[strings.ToLower(skill)] {
+			continue
+		}
+		seen[strings.ToLower(skill)] = true
+		pool = append(pool, skill)
+	}
+	return pool
+}
+
+// rollChildPassives draws one child's passives: some inherited from the pool, then
+// a few random ones from every known passive
+func rollChildPassives(rng *rand.Rand, pool []string, allSkills []string) []string {
+	child := make([]string, 0, maxPassives)
+	has := make(map[string]bool)
+
+	if len(pool) > 0 {
+		inherit := min(pickWeighted(rng, inheritWeights)+1, len(pool))
+		for _, idx := range rng.Perm(len(pool))[:inherit] {
+			child = append(child, pool[idx])
+			has[strings.ToLower(pool[idx])] = true
+		}
+	}
+
+	extra := min(pickWeighted(rng, randomWeights), maxPassives-len(child))
+	for extra > 0 && len(has) < len(allSkills) {
+		skill := allSkills[rng.Intn(len(allSkills))]
+		if has[strings.ToLower(skill)] {
+			continue
+		}
+		child = append(child, skill)
+		has[strings.ToLower(skill)] = true
+		extra--
+	}
+
+	return child
+}
+
+// passiveChance is the exact probability that rollChildPassives gives a child with
+// exactly the wanted passives, out of skillCount known passives. The simulation
+// estimates the same value by rolling children.
+func passiveChance(pool []string, wanted []string, skillCount int) float64 {
+	inPool := make(map[string]bool)
+	for _, skill := range pool {
+		inPool[strings.ToLower(skill)] = true
+	}
+	shared := 0
+	for _, skill := range wanted {
+		if inPool[strings.ToLower(skill)] {
+			shared++
+		}
+	}
+
+	chance := 0.0
+	for i, weight := range inheritWeights {
+		// 1 to 4 passives are inherited, as many as the pool has
+		inherit := min(i+1, len(pool))
+		rolled := len(wanted) - inherit
+		available := skillCount - inherit
+		if rolled < 0 || rolled > available {
+			continue
+		}
+		// Every inherited passive has to be a wanted one, and the random passives
+		// have to be exactly the wanted ones that weren't inherited
+		inheritWanted := binomial(shared, inherit) / binomial(len(pool), inherit)
+		chance += weight * inheritWanted * randomCountChance(rolled, maxPassives-inherit, available) / binomial(available, rolled)
+	}
+
+	return chance
+}
+
+// noPassiveChance is the probability that a child of parents with the given passive pool
+// carries none of the avoided passives, matching rollChildPassives. The child always
+// inherits from a non-empty pool, so a pool of avoided passives only gives 0.
+func noPassiveChance(pool []string, avoided []string, skillCount int) float64 {
+	avoid := make(map[string]bool)
+	for _, skill := range avoided {
+		avoid[strings.ToLower(skill)] = true
+	}
+	kept := 0
+	for _, skill := range pool {
+		if !avoid[strings.ToLower(skill)] {
+			kept++
+		}
+	}
+
+	chance := 0.0
+	for i, weight := range inheritWeights {
+		inherit := min(i+1, len(pool))
+		available := skillCount - inherit
+		// Nothing avoided is inherited, so every avoided passive can still be rolled
+		random := 0.0
+		for count := range randomWeights {
+			random += randomCountChance(count, maxPassives-inherit, available) *
+				binomial(available-len(avoided), count) / binomial(available, count)
+		}
+		chance += weight * binomial(kept, inherit) / binomial(len(pool), inherit) * random
+	}
+
+	return chance
+}
+
+// randomCountChance is the probability that rollChildPassives adds exactly count random
+// passives when limit passive slots are free and available passives are left to pick from
+func randomCountChance(count int, limit int, available int) float64 {
+	chance := 0.0
+	for i, weight := range randomWeights {
+		if min(i, limit, available) == count {
+			chance += weight
+		}
+	}
+	return chance
+}
+
+func binomial(n int, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// pickWeighted returns an index into weights chosen with the given probabilities
+func pickWeighted(rng *rand.Rand, weights []float64) int {
+	roll := rng.Float64()
+	for i, weight := range weights {
+		if roll < weight {
+			return i
+		}
+		roll -= weight
+	}
+	return len(weights) - 1
+}
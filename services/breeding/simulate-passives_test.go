@@ -0,0 +1,29 @@
+package breeding
+
+import (
+	"errors"
+	"palworld_tools/models"
+	"testing"
+)
+
+func TestSimulatePassivesRejectsBadInput(t *testing.T) {
+	service, _ := newTestService(t, nil, []models.PassiveSkill{{Name: "Artisan"}, {Name: "Serious"}})
+
+	if _, err := service.SimulatePassives([]string{"Artisan"}, nil, []string{"Artisan"}, MaxSimulationTrials+1, 1); !errors.Is(err, ErrTooManyTrials) {
+		t.Errorf("got %v, want ErrTooManyTrials", err)
+	}
+	if _, err := service.SimulatePassives([]string{"Nimble"}, nil, []string{"Artisan"}, 0, 1); !errors.Is(err, ErrPassiveSkillNotFound) {
+		t.Errorf("unknown parent passive: got %v, want ErrPassiveSkillNotFound", err)
+	}
+	if _, err := service.SimulatePassives(nil, nil, []string{"Nimble"}, 0, 1); !errors.Is(err, ErrPassiveSkillNotFound) {
+		t.Errorf("unknown desired passive: got %v, want ErrPassiveSkillNotFound", err)
+	}
+
+	result, err := service.SimulatePassives([]string{"artisan"}, []string{"Serious"}, []string{"Artisan"}, MaxSimulationTrials, 1)
+	if err != nil {
+		t.Fatalf("SimulatePassives: %v", err)
+	}
+	if result.Trials != MaxSimulationTrials || result.Matches == 0 {
+		t.Errorf("got %+v, want %d trials with matches", result, MaxSimulationTrials)
+	}
+}
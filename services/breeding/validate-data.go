@@ -0,0 +1,105 @@
+package breeding
+
+import (
+	"fmt"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"sort"
+	"strings"
+)
+
+// ValidateBreedingData checks the scraped Children tables for pairs listed on only
+// one parent, pairs that disagree on the child, and names missing from the paldex
+func (s *Service) ValidateBreedingData() (*dto.BreedingValidationReport, error) {
+	pals, err := s.dataService.Paldex()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.BreedingValidationReport{
+		AsymmetricPairs:  make([]dto.BreedingIssue, 0),
+		ConflictingPairs: make([]dto.BreedingConflict, 0),
+		UnresolvedNames:  make([]dto.UnresolvedName, 0),
+	}
+
+	// listed records every child claimed for a pair, by the pal page that listed it
+	listed := make(map[pairKey]map[string]bool)
+	pairNames := make(map[pairKey][2]string)
+	for _, pal := range pals {
+		for _, child := range pal.Children {
+			key := newPairKey(pal.Name, child.Parent)
+			if listed[key] == nil {
+				listed[key] = make(map[string]bool)
+				pairNames[key] = [2]string{pal.Name, child.Parent}
+			}
+			listed[key][strings.ToLower(child.Child)] = true
+
+			if models.FindPal(pals, child.Parent) == nil {
+				report.UnresolvedNames = append(report.UnresolvedNames, dto.UnresolvedName{
+					Pal: pal.Name, Name: child.Parent, Role: "parent",
+				})
+			}
+			if models.FindPal(pals, child.Child) == nil {
+				report.UnresolvedNames = append(report.UnresolvedNames, dto.UnresolvedName{
+					Pal: pal.Name, Name: child.Child, Role: "child",
+				})
+			}
+		}
+	}
+
+	for _, pal := range pals {
+		for _, child := range pal.Children {
+			parent := models.FindPal(pals, child.Parent)
+			if parent == nil {
+				continue
+			}
+			if !hasChild(parent.Children, pal.Name, child.Child) {
+				report.AsymmetricPairs = append(report.AsymmetricPairs, dto.BreedingIssue{
+					Pal: pal.Name, Parent: child.Parent, Child: child.Child,
+				})
+			}
+		}
+	}
+
+	for key, children := range listed {
+		if len(children) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(children))
+		for child := range children {
+			if pal := models.FindPal(pals, child); pal != nil {
+				child = pal.Name
+			}
+			names = append(names, child)
+		}
+		sort.Strings(names)
+		report.ConflictingPairs = append(report.ConflictingPairs, dto.BreedingConflict{
+			ParentA: pairNames[key][0], ParentB: pairNames[key][1], Children: names,
+		})
+	}
+	sort.Slice(report.ConflictingPairs, func(i, j int) bool {
+		if report.ConflictingPairs[i].ParentA != report.ConflictingPairs[j].ParentA {
+			return report.ConflictingPairs[i].ParentA < report.ConflictingPairs[j].ParentA
+		}
+		return report.ConflictingPairs[i].ParentB < report.ConflictingPairs[j].ParentB
+	})
+
+	return report, nil
+}
+
+func hasChild(children []models.Child, parent string, child string) bool {
+	for _, c := range children {
+		if strings.EqualFold(c.Parent, parent) && strings.EqualFold(c.Child, child) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintValidationSummary prints the issue counts of a validation report
+func PrintValidationSummary(report *dto.BreedingValidationReport) {
+	fmt.Println("Breeding data validation:")
+	fmt.Println("  Asymmetric pairs:", len(report.AsymmetricPairs))
+	fmt.Println("  Conflicting pairs:", len(report.ConflictingPairs))
+	fmt.Println("  Unresolved names:", len(report.UnresolvedNames))
+}
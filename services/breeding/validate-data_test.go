@@ -0,0 +1,100 @@
+package breeding
+
+import (
+	"os"
+	"palworld_tools/config"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateBreedingData(t *testing.T) {
+	cases := []struct {
+		name string
+		pals []models.Pal
+		want dto.BreedingValidationReport
+	}{
+		{"clean", []models.Pal{
+			{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+			{Name: "B", Children: []models.Child{{Parent: "a", Child: "c"}}},
+			{Name: "C"},
+		}, dto.BreedingValidationReport{}},
+		{"listed on one parent", []models.Pal{
+			{Name: "A", Children: []models.Child{{Parent: "B", Child: "C"}}},
+			{Name: "B"},
+			{Name: "C"},
+		}, dto.BreedingValidationReport{
+			AsymmetricPairs: []dto.BreedingIssue{{Pal: "A", Parent: "B", Child: "C"}},
+		}},
+		{"conflicting children", []models.Pal{
+			{Name: "A", Children: []models.Child{{Parent: "B", Child: "D"}}},
+			{Name: "B", Children: []models.Child{{Parent: "A", Child: "C"}}},
+			{Name: "C"},
+			{Name: "D"},
+		}, dto.BreedingValidationReport{
+			AsymmetricPairs: []dto.BreedingIssue{
+				{Pal: "A", Parent: "B", Child: "D"},
+				{Pal: "B", Parent: "A", Child: "C"},
+			},
+			ConflictingPairs: []dto.BreedingConflict{{ParentA: "A", ParentB: "B", Children: []string{"C", "D"}}},
+		}},
+		{"unknown names", []models.Pal{
+			{Name: "A", Children: []models.Child{{Parent: "Ghost", Child: "A"}, {Parent: "A", Child: "Phantom"}}},
+		}, dto.BreedingValidationReport{
+			UnresolvedNames: []dto.UnresolvedName{
+				{Pal: "A", Name: "Ghost", Role: "parent"},
+				{Pal: "A", Name: "Phantom", Role: "child"},
+			},
+		}},
+	}
+	for _, c := range cases {
+		service, _ := newTestService(t, c.pals, nil)
+		report, err := service.ValidateBreedingData()
+		if err != nil {
+			t.Fatalf("%s: ValidateBreedingData: %v", c.name, err)
+		}
+
+		if got := withoutEmptyLists(*report); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+// withoutEmptyLists sets empty lists to nil, so reports compare equal to literals that leave them out
+func withoutEmptyLists(report dto.BreedingValidationReport) dto.BreedingValidationReport {
+	if len(report.AsymmetricPairs) == 0 {
+		report.AsymmetricPairs = nil
+	}
+	if len(report.ConflictingPairs) == 0 {
+		report.ConflictingPairs = nil
+	}
+	if len(report.UnresolvedNames) == 0 {
+		report.UnresolvedNames = nil
+	}
+	return report
+}
+
+func TestValidateBreedingDataReportsUnreadablePaldex(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pals.json"), []byte("{broken"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	dataService, err := datamanage.NewService(&config.Config{
+		DataDir:                dir,
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	if _, err := NewService(dataService).ValidateBreedingData(); err == nil {
+		t.Errorf("ValidateBreedingData succeeded on a broken paldex")
+	}
+}
@@ -0,0 +1,266 @@
+package datamanage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	BackupReasonManual        = "manual"
+	BackupReasonUpdateData    = "update-data"
+	BackupReasonBeforeRestore = "before-restore"
+	BackupReasonDeleteProfile = "delete-profile"
+
+	DefaultBackupRetention = 10
+
+	backupTimeFormat = "20060102-150405.000"
+	backupInfoFile   = "backup.json"
+)
+
+var ErrBackupNotFound = errors.New("backup not found")
+
+func (s *Service) backupsDir() string {
+	return filepath.Join(s.dataDir, "backups")
+}
+
+// CreateBackup snapshots the paldex, passive skills, passive skill combos and the
+// pal store of every profile into a timestamped folder under backups in the data
+// directory, then drops the oldest snapshots beyond the retention limit
+func (s *Service) CreateBackup(reason string) (*dto.Backup, error) {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	return s.createBackupLocked(reason, "")
+}
+
+// createBackupLocked takes a snapshot while the caller holds storeMutex.
+// The snapshot with ID keep is never pruned.
+func (s *Service) createBackupLocked(reason string, keep string) (*dto.Backup, error) {
+	pals, err := s.ReadPaldex()
+	if err != nil {
+		return nil, err
+	}
+	passiveSkills, err := s.ReadPassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+	combos, err := s.ReadPassiveSkillCombos()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := s.store.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	palStores := make(map[string][]models.PalSpecies)
+	for _, profile := range profiles {
+		palStore, err := s.ReadStoredPals(profile)
+		if err != nil {
+			return nil, err
+		}
+		palStores[profile] = palStore
+	}
+
+	now := time.Now()
+	id := now.UTC().Format(backupTimeFormat)
+	path := filepath.Join(s.backupsDir(), id)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", id)
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+
+	snapshot := NewJSONStore(DefaultDataFiles(path))
+	if err := snapshot.WritePaldex(pals); err != nil {
+		return nil, err
+	}
+	if err := snapshot.WritePassiveSkills(passiveSkills); err != nil {
+		return nil, err
+	}
+	if err := snapshot.WritePassiveSkillCombos(combos); err != nil {
+		return nil, err
+	}
+	var storedPals int
+	for _, profile := range profiles {
+		if profile != DefaultProfile {
+			if err := snapshot.CreateProfile(profile); err != nil {
+				return nil, err
+			}
+		}
+		if err := snapshot.WriteStoredPals(profile, palStores[profile]); err != nil {
+			return nil, err
+		}
+		storedPals += len(speciesPals(palStores[profile]))
+	}
+
+	backup := &dto.Backup{
+		Id:                 id,
+		Time:               now,
+		Reason:             reason,
+		Pals:               len(pals),
+		PassiveSkills:      len(passiveSkills),
+		PassiveSkillCombos: len(combos),
+		StoredPals:         storedPals,
+		Profiles:           len(profiles),
+	}
+	// The info file is written last, so a folder without it is an unfinished snapshot
+	if err := snapshot.writeFile(backupInfoFile, backup); err != nil {
+		return nil, err
+	}
+	fmt.Println("Backup created:", id)
+
+	if err := s.pruneBackups(keep); err != nil {
+		fmt.Println("Error removing old backups:", err)
+	}
+
+	return backup, nil
+}
+
+// ListBackups returns every finished snapshot, newest first
+func (s *Service) ListBackups() ([]dto.Backup, error) {
+	entries, err := os.ReadDir(s.backupsDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return []dto.Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]dto.Backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		backup, err := s.readBackupInfo(entry.Name())
+		if err != nil {
+			continue
+		}
+		backups = append(backups, *backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Id > backups[j].Id
+	})
+
+	return backups, nil
+}
+
+func (s *Service) readBackupInfo(id string) (*dto.Backup, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return nil, ErrBackupNotFound
+	}
+	snapshot := NewJSONStore(DefaultDataFiles(filepath.Join(s.backupsDir(), id)))
+	var backup dto.Backup
+	if err := snapshot.readFile(dataKindBackupInfo, backupInfoFile, &backup); err != nil {
+		return nil, err
+	}
+	if backup.Id == "" {
+		return nil, ErrBackupNotFound
+	}
+	return &backup, nil
+}
+
+// pruneBackups removes the oldest snapshots beyond the retention limit, except keep
+func (s *Service) pruneBackups(keep string) error {
+	if s.backupRetention <= 0 {
+		return nil
+	}
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(s.backupRetention, len(backups)):] {
+		if backup.Id == keep {
+			continue
+		}
+		fmt.Println("Removing old backup:", backup.Id)
+		if err := os.RemoveAll(filepath.Join(s.backupsDir(), backup.Id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreBackup writes a snapshot back over the current data. The current data is
+// snapshotted first, and the change to each profile's pal store is recorded in its journal
+// so it can be undone. Profiles missing from the current data are created again, profiles
+// created after the snapshot are left as they are.
+func (s *Service) RestoreBackup(id string, source string) (*dto.Backup, error) {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	backup, err := s.readBackupInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := NewJSONStore(DefaultDataFiles(filepath.Join(s.backupsDir(), id)))
+	pals, err := snapshot.ReadPaldex()
+	if err != nil {
+		return nil, err
+	}
+	passiveSkills, err := snapshot.ReadPassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+	combos, err := snapshot.ReadPassiveSkillCombos()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := snapshot.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+	palStores := make(map[string][]models.PalSpecies)
+	for _, profile := range profiles {
+		palStore, err := snapshot.ReadStoredPals(profile)
+		if err != nil {
+			return nil, err
+		}
+		if palStore == nil {
+			palStore = []models.PalSpecies{}
+		}
+		palStores[profile] = palStore
+	}
+
+	if _, err := s.createBackupLocked(BackupReasonBeforeRestore, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.WritePaldex(pals); err != nil {
+		return nil, err
+	}
+	if err := s.WritePassiveSkills(passiveSkills); err != nil {
+		return nil, err
+	}
+	if err := s.WritePassiveSkillCombos(combos); err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		exists, err := s.ProfileExists(profile)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err := s.store.CreateProfile(profile); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = s.commitStoredPals(profile, ActionRestore, source, 0, func([]models.PalSpecies) ([]models.PalSpecies, error) {
+			return palStores[profile], nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	fmt.Println("Backup restored:", id)
+
+	return backup, nil
+}
@@ -0,0 +1,43 @@
+package datamanage
+
+import (
+	"os"
+	"palworld_tools/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreBackupFromBeforeUniqueIDs(t *testing.T) {
+	service := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+	if _, err := service.AddPal(DefaultProfile, "Bushi", models.StoredPal{Gender: "f"}, SourceAPI); err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	backup, err := service.CreateBackup(BackupReasonManual)
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+
+	// Turn the snapshot into one taken before stored pals had unique IDs
+	path := filepath.Join(service.backupsDir(), backup.Id, "stored_pals.json")
+	if err := os.WriteFile(path, []byte(legacyStoredPals), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := service.RestoreBackup(backup.Id, SourceAPI); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	palStore, err := service.ReadStoredPals(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, pal := range speciesPals(palStore) {
+		if pal.Pal.ID == "" || seen[pal.Pal.ID] {
+			t.Fatalf("ID %q is empty or used twice", pal.Pal.ID)
+		}
+		seen[pal.Pal.ID] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("got %d stored pals, want 3", len(seen))
+	}
+}
@@ -0,0 +1,185 @@
+package datamanage
+
+import (
+	"fmt"
+	"palworld_tools/models"
+	"strings"
+	"time"
+)
+
+// dataCache holds the paldex, passive skills and passive skill combos with
+// case-insensitive indexes. It is never changed once built, a reload swaps in a new one.
+type dataCache struct {
+	version       string
+	pals          []models.Pal
+	palsByName    map[string]int
+	passiveSkills []models.PassiveSkill
+	skillsByName  map[string]int
+	combos        []models.PassiveSkillCombo
+	combosByName  map[string]int
+}
+
+// ReloadData reads the paldex, passive skills and passive skill combos from the
+// store and swaps them into the cache
+func (s *Service) ReloadData() error {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+
+	return s.reloadDataLocked()
+}
+
+func (s *Service) reloadDataLocked() error {
+	// Read the version first, so a change during the reload is picked up by the next check
+	version, err := s.store.DataVersion()
+	if err != nil {
+		return err
+	}
+	pals, err := s.store.ReadPaldex()
+	if err != nil {
+		return err
+	}
+	passiveSkills, err := s.store.ReadPassiveSkills()
+	if err != nil {
+		return err
+	}
+	combos, err := s.store.ReadPassiveSkillCombos()
+	if err != nil {
+		return err
+	}
+
+	cache := &dataCache{
+		version:       version,
+		pals:          pals,
+		palsByName:    make(map[string]int, len(pals)),
+		passiveSkills: passiveSkills,
+		skillsByName:  make(map[string]int, len(passiveSkills)),
+		combos:        combos,
+		combosByName:  make(map[string]int, len(combos)),
+	}
+	// The first entry wins, like the linear scans in models
+	for i := len(pals) - 1; i >= 0; i-- {
+		cache.palsByName[strings.ToLower(pals[i].Name)] = i
+	}
+	for i := len(passiveSkills) - 1; i >= 0; i-- {
+		cache.skillsByName[strings.ToLower(passiveSkills[i].Name)] = i
+	}
+	for i := len(combos) - 1; i >= 0; i-- {
+		cache.combosByName[strings.ToLower(combos[i].Name)] = i
+	}
+
+	s.cache.Store(cache)
+	return nil
+}
+
+// loadDataCache returns the current cache, loading it on first use
+func (s *Service) loadDataCache() (*dataCache, error) {
+	if cache := s.cache.Load(); cache != nil {
+		return cache, nil
+	}
+
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	if cache := s.cache.Load(); cache != nil {
+		return cache, nil
+	}
+	if err := s.reloadDataLocked(); err != nil {
+		return nil, err
+	}
+
+	return s.cache.Load(), nil
+}
+
+// WatchData checks the store for changes made outside the server, such as an edited
+// data file, every interval and reloads the cache when it changed. onReload is called
+// after every such reload. It returns at once and keeps checking in the background.
+func (s *Service) WatchData(interval time.Duration, onReload func()) {
+	go func() {
+		for range time.Tick(interval) {
+			changed, err := s.reloadIfChanged()
+			if err != nil {
+				fmt.Println("Error reloading data:", err)
+				continue
+			}
+			if changed {
+				fmt.Println("Data files changed, cache reloaded")
+				onReload()
+			}
+		}
+	}()
+}
+
+// reloadIfChanged reloads the cache if the store changed since it was loaded
+func (s *Service) reloadIfChanged() (bool, error) {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+
+	version, err := s.store.DataVersion()
+	if err != nil {
+		return false, err
+	}
+	if cache := s.cache.Load(); cache != nil && cache.version == version {
+		return false, nil
+	}
+
+	return true, s.reloadDataLocked()
+}
+
+// Paldex returns the cached paldex. The slice is shared and must not be modified.
+func (s *Service) Paldex() ([]models.Pal, error) {
+	cache, err := s.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.pals, nil
+}
+
+// FindPal returns the paldex entry for name, ignoring case, or nil if there is none
+func (s *Service) FindPal(name string) (*models.Pal, error) {
+	cache, err := s.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+	i, ok := cache.palsByName[strings.ToLower(name)]
+	if !ok {
+		return nil, nil
+	}
+	pal := cache.pals[i]
+	return &pal, nil
+}
+
+// PassiveSkills returns the cached passive skills. The slice is shared and must not be modified.
+func (s *Service) PassiveSkills() ([]models.PassiveSkill, error) {
+	cache, err := s.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.passiveSkills, nil
+}
+
+// FindPassiveSkill returns the passive skill called name, ignoring case, or nil if there is none
+func (s *Service) FindPassiveSkill(name string) (*models.PassiveSkill, error) {
+	cache, err := s.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+	i, ok := cache.skillsByName[strings.ToLower(name)]
+	if !ok {
+		return nil, nil
+	}
+	skill := cache.passiveSkills[i]
+	return &skill, nil
+}
+
+// FindPassiveSkillCombo returns the passive skill combo called name, ignoring case, or nil if there is none
+func (s *Service) FindPassiveSkillCombo(name string) (*models.PassiveSkillCombo, error) {
+	cache, err := s.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+	i, ok := cache.combosByName[strings.ToLower(name)]
+	if !ok {
+		return nil, nil
+	}
+	combo := cache.combos[i]
+	return &combo, nil
+}
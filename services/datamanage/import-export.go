@@ -0,0 +1,404 @@
+package datamanage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TransferFormatCSV  = "csv"
+	TransferFormatJSON = "json"
+
+	ImportModeMerge   = "merge"
+	ImportModeReplace = "replace"
+)
+
+var (
+	ErrUnknownTransferFormat = errors.New("unknown format, use csv or json")
+	ErrUnknownImportMode     = errors.New("unknown import mode, use merge or replace")
+)
+
+// csvColumns is the header of an exported CSV file. Lists are joined with ";".
+var csvColumns = []string{
+	"id", "name", "gender", "nickname", "level",
+	"talent_hp", "talent_attack", "talent_defense",
+	"condensation_rank", "container", "passive_skills", "active_skills",
+}
+
+const csvListSeparator = ";"
+
+// ImportError lists every row of an import that failed validation
+type ImportError struct {
+	Rows []dto.ImportRowError
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%d rows failed validation", len(e.Rows))
+}
+
+// importedPal is a validated import row
+type importedPal struct {
+	species string
+	pal     models.StoredPal
+}
+
+// ExportStore returns every stored pal as a CSV or JSON file
+func (s *Service) ExportStore(profile string, format string) ([]byte, error) {
+	format = strings.ToLower(format)
+	if format != TransferFormatCSV && format != TransferFormatJSON {
+		return nil, ErrUnknownTransferFormat
+	}
+
+	palStore, err := s.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dto.StorePalRecord, 0)
+	for _, species := range palStore {
+		for _, pal := range species.StoredPals {
+			records = append(records, palRecord(species.Name, pal))
+		}
+	}
+
+	if format == TransferFormatJSON {
+		return json.MarshalIndent(records, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(csvColumns)
+	for _, record := range records {
+		writer.Write([]string{
+			record.Id,
+			record.Name,
+			record.Gender,
+			record.Nickname,
+			strconv.Itoa(record.Level),
+			strconv.Itoa(record.Talents.HP),
+			strconv.Itoa(record.Talents.Attack),
+			strconv.Itoa(record.Talents.Defense),
+			strconv.Itoa(record.CondensationRank),
+			record.Container,
+			strings.Join(record.PassiveSkills, csvListSeparator),
+			strings.Join(record.ActiveSkills, csvListSeparator),
+		})
+	}
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// ImportStore loads stored pals from a CSV or JSON file. Every row is validated
+// against the paldex and passive skills first, and nothing is written if any row fails.
+// In merge mode rows with the ID of a stored pal update it and other rows are added.
+// In replace mode the store is replaced by the imported rows. Only IDs of pals already
+// stored in the profile are kept, every other row gets a new ID.
+func (s *Service) ImportStore(profile string, data []byte, format string, mode string) (*dto.ImportResult, error) {
+	mode, err := importMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dto.StorePalRecord
+	var rowNumbers []int
+	var rowErrors []dto.ImportRowError
+	switch strings.ToLower(format) {
+	case TransferFormatCSV:
+		records, rowNumbers, rowErrors, err = parseCSVRecords(data)
+	case TransferFormatJSON:
+		records, rowNumbers, err = parseJSONRecords(data)
+	default:
+		return nil, ErrUnknownTransferFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := s.validateRecords(records, rowNumbers, rowErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importPals(profile, imported, mode, false)
+}
+
+// ImportRecords loads stored pals from records built elsewhere, such as a save file,
+// with the same validation and modes as ImportStore. Rows are numbered from 1.
+// Record IDs that are UUIDs are kept even for new pals, so importing the same save
+// again updates the pals by their instance ID.
+func (s *Service) ImportRecords(profile string, records []dto.StorePalRecord, mode string) (*dto.ImportResult, error) {
+	mode, err := importMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	rowNumbers := make([]int, len(records))
+	for i := range records {
+		rowNumbers[i] = i + 1
+	}
+	imported, err := s.validateRecords(records, rowNumbers, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importPals(profile, imported, mode, true)
+}
+
+// importPals writes validated pals to the store in a single update. Pals without an ID
+// get a new one, as do pals whose ID isn't stored in the profile unless keepNewIDs is set.
+func (s *Service) importPals(profile string, imported []importedPal, mode string, keepNewIDs bool) (*dto.ImportResult, error) {
+	result := &dto.ImportResult{Mode: mode}
+	err := s.updateStoredPals(profile, ActionImport, SourceImport, func(palStore []models.PalSpecies) ([]models.PalSpecies, error) {
+		storedIDs := make(map[string]bool)
+		for _, species := range palStore {
+			for _, pal := range species.StoredPals {
+				storedIDs[pal.ID] = true
+			}
+		}
+
+		if mode == ImportModeReplace {
+			for _, species := range palStore {
+				result.Removed += len(species.StoredPals)
+			}
+			palStore = nil
+		}
+
+		for _, pal := range imported {
+			if pal.pal.ID == "" || (!storedIDs[pal.pal.ID] && !keepNewIDs) {
+				pal.pal.ID = newPalID()
+			}
+			if i, j, ok := findStoredPal(palStore, pal.pal.ID); ok {
+				// Drop the stored pal and add it back so species changes are applied
+				palStore[i].StoredPals = append(palStore[i].StoredPals[:j], palStore[i].StoredPals[j+1:]...)
+				if len(palStore[i].StoredPals) == 0 {
+					palStore = append(palStore[:i], palStore[i+1:]...)
+				}
+				result.Updated++
+			} else {
+				result.Added++
+			}
+			palStore = addToSpecies(palStore, pal.species, pal.pal)
+		}
+
+		if palStore == nil {
+			palStore = []models.PalSpecies{}
+		}
+		return palStore, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Imported stored pals:", result.Added, "added,", result.Updated, "updated,", result.Removed, "removed")
+
+	return result, nil
+}
+
+// importMode checks mode, defaulting to merge
+func importMode(mode string) (string, error) {
+	mode = strings.ToLower(mode)
+	if mode == "" {
+		return ImportModeMerge, nil
+	}
+	if mode != ImportModeMerge && mode != ImportModeReplace {
+		return "", ErrUnknownImportMode
+	}
+	return mode, nil
+}
+
+func palRecord(species string, pal models.StoredPal) dto.StorePalRecord {
+	return dto.StorePalRecord{
+		Id: pal.ID,
+		AddPalRequest: dto.AddPalRequest{
+			Name:             species,
+			Gender:           pal.Gender,
+			Nickname:         pal.Nickname,
+			Level:            pal.Level,
+			Talents:          dto.Talents(pal.Talents),
+			CondensationRank: pal.CondensationRank,
+			Container:        pal.Container,
+			PassiveSkills:    nonNil(pal.PassiveSkills),
+			ActiveSkills:     nonNil(pal.ActiveSkills),
+		},
+	}
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// parseJSONRecords reads a JSON array of records, numbering rows from 1
+func parseJSONRecords(data []byte) ([]dto.StorePalRecord, []int, error) {
+	var records []dto.StorePalRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, nil, fmt.Errorf("invalid json: %v", err)
+	}
+
+	rowNumbers := make([]int, len(records))
+	for i := range records {
+		rowNumbers[i] = i + 1
+	}
+	return records, rowNumbers, nil
+}
+
+// parseCSVRecords reads a CSV file with a header row. Columns can be in any order
+// and only name and gender are required. Rows are numbered by their line in the file.
+// Rows with values that can't be parsed are returned as row errors.
+func parseCSVRecords(data []byte) ([]dto.StorePalRecord, []int, []dto.ImportRowError, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid csv header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "gender"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, nil, fmt.Errorf("csv is missing the %s column", required)
+		}
+	}
+
+	records := make([]dto.StorePalRecord, 0)
+	rowNumbers := make([]int, 0)
+	rowErrors := make([]dto.ImportRowError, 0)
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		row, _ := reader.FieldPos(0)
+
+		var rowErr error
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+		number := func(column string) int {
+			text := value(column)
+			if text == "" || rowErr != nil {
+				return 0
+			}
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				rowErr = fmt.Errorf("%s must be a number", column)
+			}
+			return n
+		}
+		list := func(column string) []string {
+			items := make([]string, 0)
+			for _, item := range strings.Split(value(column), csvListSeparator) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return items
+		}
+
+		record := dto.StorePalRecord{
+			Id: value("id"),
+			AddPalRequest: dto.AddPalRequest{
+				Name:     value("name"),
+				Gender:   value("gender"),
+				Nickname: value("nickname"),
+				Level:    number("level"),
+				Talents: dto.Talents{
+					HP:      number("talent_hp"),
+					Attack:  number("talent_attack"),
+					Defense: number("talent_defense"),
+				},
+				CondensationRank: number("condensation_rank"),
+				Container:        value("container"),
+				PassiveSkills:    list("passive_skills"),
+				ActiveSkills:     list("active_skills"),
+			},
+		}
+		if rowErr != nil {
+			rowErrors = append(rowErrors, dto.ImportRowError{Row: row, Error: rowErr.Error()})
+			continue
+		}
+
+		records = append(records, record)
+		rowNumbers = append(rowNumbers, row)
+	}
+
+	return records, rowNumbers, rowErrors, nil
+}
+
+// validateRecords checks every record and returns them as stored pals, or an
+// ImportError listing every row that failed, including rows that failed parsing
+func (s *Service) validateRecords(records []dto.StorePalRecord, rowNumbers []int, rowErrors []dto.ImportRowError) ([]importedPal, error) {
+	imported := make([]importedPal, 0, len(records))
+	seenIDs := make(map[string]int)
+	for i, record := range records {
+		row := rowNumbers[i]
+		fail := func(err error) {
+			rowErrors = append(rowErrors, dto.ImportRowError{Row: row, Error: err.Error()})
+		}
+
+		species, err := s.FindPal(record.Name)
+		if err != nil {
+			return nil, err
+		}
+		if species == nil {
+			fail(fmt.Errorf("pal name not found: %s", record.Name))
+			continue
+		}
+		// IDs that aren't UUIDs are dropped, importPals gives those pals a new ID
+		id, _ := parsePalID(record.Id)
+		if id != "" {
+			if firstRow, seen := seenIDs[id]; seen {
+				fail(fmt.Errorf("id %s is already used by row %d", id, firstRow))
+				continue
+			}
+			seenIDs[id] = row
+		}
+
+		pal := models.StoredPal{
+			ID:               id,
+			Gender:           record.Gender,
+			Nickname:         record.Nickname,
+			Level:            record.Level,
+			Talents:          models.Talents(record.Talents),
+			CondensationRank: record.CondensationRank,
+			Container:        record.Container,
+			PassiveSkills:    record.PassiveSkills,
+			ActiveSkills:     record.ActiveSkills,
+		}
+		if err := s.validateStoredPal(&pal); err != nil {
+			fail(err)
+			continue
+		}
+
+		imported = append(imported, importedPal{species: species.Name, pal: pal})
+	}
+
+	if len(rowErrors) > 0 {
+		sort.Slice(rowErrors, func(i, j int) bool {
+			return rowErrors[i].Row < rowErrors[j].Row
+		})
+		return nil, &ImportError{Rows: rowErrors}
+	}
+	return imported, nil
+}
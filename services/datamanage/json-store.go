@@ -0,0 +1,388 @@
+package datamanage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"palworld_tools/models"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DataFiles names the data directory and the JSON file of each kind of data in it
+type DataFiles struct {
+	Dir                string
+	Pals               string
+	StoredPals         string
+	PassiveSkills      string
+	PassiveSkillCombos string
+}
+
+// DefaultDataFiles returns the standard file names inside dir
+func DefaultDataFiles(dir string) DataFiles {
+	return DataFiles{
+		Dir:                dir,
+		Pals:               "pals.json",
+		StoredPals:         "stored_pals.json",
+		PassiveSkills:      "passive_skills.json",
+		PassiveSkillCombos: "passive_skill_combos.json",
+	}
+}
+
+// JSONStore keeps each kind of data in its own JSON file in the data directory. The
+// default profile keeps its pal store there too, other profiles under profiles/<name>.
+type JSONStore struct {
+	files DataFiles
+
+	// fileMutex keeps schema upgrades from overwriting a data file written at the same time
+	fileMutex sync.Mutex
+}
+
+func NewJSONStore(files DataFiles) *JSONStore {
+	return &JSONStore{files: files}
+}
+
+func (s *JSONStore) ReadPaldex() ([]models.Pal, error) {
+	var pals []models.Pal
+	err := s.readFile(dataKindPals, s.files.Pals, &pals)
+	return pals, err
+}
+
+func (s *JSONStore) WritePaldex(pals []models.Pal) error {
+	return s.writeFile(s.files.Pals, pals)
+}
+
+func (s *JSONStore) ReadPassiveSkills() ([]models.PassiveSkill, error) {
+	var passiveSkills []models.PassiveSkill
+	err := s.readFile(dataKindPassiveSkills, s.files.PassiveSkills, &passiveSkills)
+	return passiveSkills, err
+}
+
+func (s *JSONStore) WritePassiveSkills(passiveSkills []models.PassiveSkill) error {
+	return s.writeFile(s.files.PassiveSkills, passiveSkills)
+}
+
+func (s *JSONStore) ReadPassiveSkillCombos() ([]models.PassiveSkillCombo, error) {
+	var combos []models.PassiveSkillCombo
+	err := s.readFile(dataKindPassiveSkillCombos, s.files.PassiveSkillCombos, &combos)
+	return combos, err
+}
+
+func (s *JSONStore) WritePassiveSkillCombos(combos []models.PassiveSkillCombo) error {
+	return s.writeFile(s.files.PassiveSkillCombos, combos)
+}
+
+func (s *JSONStore) ReadStoredPals(profile string) ([]models.PalSpecies, error) {
+	var palStore []models.PalSpecies
+	err := s.readFile(dataKindStoredPals, profileFile(profile, s.files.StoredPals), &palStore)
+	return palStore, err
+}
+
+func (s *JSONStore) WriteStoredPals(profile string, palStore []models.PalSpecies) error {
+	return s.writeFile(profileFile(profile, s.files.StoredPals), palStore)
+}
+
+func (s *JSONStore) ReadStoreEvents(profile string) ([]models.StoreEvent, error) {
+	file, err := os.Open(filepath.Join(s.files.Dir, profileFile(profile, "store_journal.jsonl")))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []models.StoreEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var event models.StoreEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			fmt.Printf("Error parsing existing store_journal.jsonl: %v\n", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+// AppendStoreEvent adds one line to the journal, which is only ever cut back by RemoveLastStoreEvent
+func (s *JSONStore) AppendStoreEvent(profile string, event models.StoreEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.files.Dir, profileFile(profile, "store_journal.jsonl")), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// LastStoreEvent reads the journal backwards from its end up to the start of the last line
+func (s *JSONStore) LastStoreEvent(profile string) (*models.StoreEvent, error) {
+	file, err := os.Open(filepath.Join(s.files.Dir, profileFile(profile, "store_journal.jsonl")))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	event, _, err := lastJournalEvent(file)
+	return event, err
+}
+
+// RemoveLastStoreEvent cuts the last line off the journal if it holds the event with the given ID
+func (s *JSONStore) RemoveLastStoreEvent(profile string, id int) error {
+	file, err := os.OpenFile(filepath.Join(s.files.Dir, profileFile(profile, "store_journal.jsonl")), os.O_RDWR, 0644)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	event, start, err := lastJournalEvent(file)
+	if err != nil || event == nil || event.ID != id {
+		return err
+	}
+	if err := file.Truncate(start); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// lastJournalEvent returns the event on the last line of a journal and the offset the line
+// starts at, reading backwards from the end
+func lastJournalEvent(file *os.File) (*models.StoreEvent, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var tail []byte
+	start := int64(0)
+	size := int64(4096)
+	for offset := info.Size(); offset > 0; size *= 2 {
+		size = min(size, offset)
+		offset -= size
+		chunk := make([]byte, size, size+int64(len(tail)))
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return nil, 0, err
+		}
+		tail = append(chunk, tail...)
+
+		line := bytes.TrimRight(tail, " \t\r\n")
+		if i := bytes.LastIndexByte(line, '\n'); i >= 0 {
+			tail = line[i+1:]
+			start = offset + int64(i) + 1
+			break
+		}
+	}
+
+	line := bytes.TrimSpace(tail)
+	if len(line) == 0 {
+		return nil, start, nil
+	}
+	var event models.StoreEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		fmt.Printf("Error parsing existing store_journal.jsonl: %v\n", err)
+		return nil, 0, err
+	}
+	return &event, start, nil
+}
+
+// DataVersion combines the modification time and size of the paldex, passive skill
+// and passive skill combo files
+func (s *JSONStore) DataVersion() (string, error) {
+	var version strings.Builder
+	for _, name := range []string{s.files.Pals, s.files.PassiveSkills, s.files.PassiveSkillCombos} {
+		info, err := os.Stat(filepath.Join(s.files.Dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			version.WriteString("-;")
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&version, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+	}
+	return version.String(), nil
+}
+
+// profileFile returns the path of a profile's data file relative to the data directory
+func profileFile(profile string, name string) string {
+	if profile == DefaultProfile {
+		return name
+	}
+	return filepath.Join("profiles", profile, name)
+}
+
+// ListProfiles returns the default profile followed by every folder under profiles
+func (s *JSONStore) ListProfiles() ([]string, error) {
+	profiles := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(s.files.Dir, "profiles"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultProfile {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	return profiles, nil
+}
+
+func (s *JSONStore) CreateProfile(name string) error {
+	if err := os.MkdirAll(filepath.Join(s.files.Dir, "profiles", name), 0755); err != nil {
+		return err
+	}
+	return s.WriteStoredPals(name, []models.PalSpecies{})
+}
+
+func (s *JSONStore) RenameProfile(name string, newName string) error {
+	return os.Rename(filepath.Join(s.files.Dir, "profiles", name), filepath.Join(s.files.Dir, "profiles", newName))
+}
+
+func (s *JSONStore) DeleteProfile(name string) error {
+	return os.RemoveAll(filepath.Join(s.files.Dir, "profiles", name))
+}
+
+// readFile parses a data file of the given kind into v, leaving v empty if the file
+// doesn't exist. Files with an older schema version are upgraded first.
+func (s *JSONStore) readFile(kind string, name string, v any) error {
+	path := filepath.Join(s.files.Dir, name)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	envelope, err := parseEnvelope(content)
+	if err == nil && envelope.SchemaVersion != SchemaVersion {
+		envelope, err = s.upgradeFile(kind, path)
+	}
+	if err == nil {
+		err = json.Unmarshal(envelope.Data, v)
+	}
+	if err != nil {
+		fmt.Printf("Error parsing existing %s: %v\n", name, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *JSONStore) writeFile(name string, v any) error {
+	// Convert the slice to JSON
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.MarshalIndent(dataEnvelope{SchemaVersion: SchemaVersion, Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write the JSON data to a file
+	s.fileMutex.Lock()
+	defer s.fileMutex.Unlock()
+	return writeFileAtomic(filepath.Join(s.files.Dir, name), jsonData)
+}
+
+// upgradeFile migrates the data file at path to the current schema version and
+// writes it back, keeping a copy of the original
+func (s *JSONStore) upgradeFile(kind string, path string) (dataEnvelope, error) {
+	s.fileMutex.Lock()
+	defer s.fileMutex.Unlock()
+
+	// Read again under the lock, the file may have been written or upgraded meanwhile
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return dataEnvelope{}, err
+	}
+	original, err := parseEnvelope(content)
+	if err != nil || original.SchemaVersion == SchemaVersion {
+		return original, err
+	}
+
+	envelope, err := migrateEnvelope(kind, original)
+	if err != nil {
+		return envelope, err
+	}
+	if err := backupBeforeUpgrade(path, content, original.SchemaVersion); err != nil {
+		return envelope, err
+	}
+	jsonData, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return envelope, err
+	}
+	if err := writeFileAtomic(path, jsonData); err != nil {
+		return envelope, err
+	}
+
+	fmt.Printf("Upgraded %s from schema version %d to %d\n", path, original.SchemaVersion, envelope.SchemaVersion)
+	return envelope, nil
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into place,
+// so readers and crashes never see a half written file
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// Flush the rename itself to disk
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer dirFile.Close()
+	return dirFile.Sync()
+}
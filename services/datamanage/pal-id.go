@@ -0,0 +1,37 @@
+package datamanage
+
+import (
+	"errors"
+	"palworld_tools/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrStoredPalNotFound = errors.New("stored pal not found")
+
+// newPalID returns a globally unique ID for a stored pal. IDs are never reused,
+// so an ID cached by a client or referenced by a plan always points at the same pal.
+func newPalID() string {
+	return uuid.NewString()
+}
+
+// parsePalID returns id in the form newPalID uses, or false if it is not a UUID
+func parsePalID(id string) (string, bool) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+// findStoredPal returns the species and pal index of the stored pal with the given ID
+func findStoredPal(palStore []models.PalSpecies, id string) (int, int, bool) {
+	for i, species := range palStore {
+		for j, pal := range species.StoredPals {
+			if pal.ID == id {
+				return i, j, true
+			}
+		}
+	}
+	return -1, -1, false
+}
@@ -0,0 +1,144 @@
+package datamanage
+
+import (
+	"errors"
+	"fmt"
+	"palworld_tools/dto"
+	"regexp"
+	"slices"
+)
+
+// DefaultProfile is the pal store used when no profile is selected. It always
+// exists and holds the pals stored before profiles were added.
+const DefaultProfile = "default"
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileExists   = errors.New("profile already exists")
+	ErrDefaultProfile  = errors.New("the default profile can't be renamed or deleted")
+)
+
+// profileNamePattern keeps profile names usable as folder names and in URLs
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use up to 64 lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// requireProfile returns ErrProfileNotFound unless the profile exists
+func (s *Service) requireProfile(profile string) error {
+	if profile == DefaultProfile {
+		return nil
+	}
+	if validateProfileName(profile) != nil {
+		return ErrProfileNotFound
+	}
+	profiles, err := s.store.ListProfiles()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(profiles, profile) {
+		return ErrProfileNotFound
+	}
+	return nil
+}
+
+// ProfileExists reports whether a profile with the given name exists
+func (s *Service) ProfileExists(profile string) (bool, error) {
+	err := s.requireProfile(profile)
+	if errors.Is(err, ErrProfileNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListProfiles returns every profile with the number of pals in its store,
+// the default profile first
+func (s *Service) ListProfiles() ([]dto.Profile, error) {
+	names, err := s.store.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]dto.Profile, 0, len(names))
+	for _, name := range names {
+		palStore, err := s.store.ReadStoredPals(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, dto.Profile{Name: name, StoredPals: len(speciesPals(palStore))})
+	}
+	return profiles, nil
+}
+
+// CreateProfile adds a profile with an empty pal store and journal
+func (s *Service) CreateProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	exists, err := s.ProfileExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrProfileExists
+	}
+
+	fmt.Println("Creating profile:", name)
+	return s.store.CreateProfile(name)
+}
+
+// RenameProfile gives a profile a new name. Its pals and journal move along.
+func (s *Service) RenameProfile(name string, newName string) error {
+	if name == DefaultProfile || newName == DefaultProfile {
+		return ErrDefaultProfile
+	}
+	if err := validateProfileName(newName); err != nil {
+		return err
+	}
+
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	if err := s.requireProfile(name); err != nil {
+		return err
+	}
+	exists, err := s.ProfileExists(newName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrProfileExists
+	}
+
+	fmt.Println("Renaming profile", name, "to", newName)
+	return s.store.RenameProfile(name, newName)
+}
+
+// DeleteProfile removes a profile with its pals and journal. A backup is taken
+// first so the pals can still be restored.
+func (s *Service) DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return ErrDefaultProfile
+	}
+
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	if err := s.requireProfile(name); err != nil {
+		return err
+	}
+	if _, err := s.createBackupLocked(BackupReasonDeleteProfile, ""); err != nil {
+		return err
+	}
+
+	fmt.Println("Deleting profile:", name)
+	return s.store.DeleteProfile(name)
+}
@@ -0,0 +1,187 @@
+package datamanage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// SchemaVersion is the layout of the JSON data files written by this build.
+// Files without a version are version 1.
+const SchemaVersion = 3
+
+// Kinds of JSON data file, so a migration can change only the files it is about
+const (
+	dataKindPals               = "pals"
+	dataKindPassiveSkills      = "passive_skills"
+	dataKindPassiveSkillCombos = "passive_skill_combos"
+	dataKindStoredPals         = "stored_pals"
+	dataKindBackupInfo         = "backup_info"
+)
+
+// dataEnvelope wraps the contents of every JSON data file with the schema version
+// it was written with
+type dataEnvelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// schemaMigration upgrades the data of one kind of file to version from the version before
+type schemaMigration struct {
+	version     int
+	description string
+	migrate     func(kind string, data json.RawMessage) (json.RawMessage, error)
+}
+
+// schemaMigrations lists every upgrade in version order. When a change to the models
+// would break existing files, add a migration here and bump SchemaVersion. A migration
+// must leave data it already upgraded unchanged, since a file can be migrated again
+// if the process stops before the upgraded file is written.
+var schemaMigrations = []schemaMigration{
+	{
+		version:     2,
+		description: "wrap the data in a versioned envelope",
+		migrate: func(kind string, data json.RawMessage) (json.RawMessage, error) {
+			// The envelope is added when the file is written, the data stays the same
+			return data, nil
+		},
+	},
+	{
+		version:     3,
+		description: "give every stored pal a unique ID",
+		migrate: func(kind string, data json.RawMessage) (json.RawMessage, error) {
+			if kind != dataKindStoredPals {
+				return data, nil
+			}
+			return assignStoredPalIDs(data)
+		},
+	},
+}
+
+// assignStoredPalIDs replaces the old per-species numeric IDs, and missing IDs,
+// with new unique IDs. Pals that already have one keep it.
+func assignStoredPalIDs(data json.RawMessage) (json.RawMessage, error) {
+	var palStore []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &palStore); err != nil {
+		return nil, err
+	}
+
+	var assigned int
+	for _, species := range palStore {
+		key := fieldKey(species, "StoredPals")
+		if len(species[key]) == 0 {
+			continue
+		}
+		var storedPals []map[string]json.RawMessage
+		if err := json.Unmarshal(species[key], &storedPals); err != nil {
+			return nil, err
+		}
+
+		for _, pal := range storedPals {
+			var id string
+			idKey := fieldKey(pal, "ID")
+			if json.Unmarshal(pal[idKey], &id) == nil && id != "" {
+				continue
+			}
+			newID, err := json.Marshal(newPalID())
+			if err != nil {
+				return nil, err
+			}
+			delete(pal, idKey)
+			pal["ID"] = newID
+			assigned++
+		}
+
+		updated, err := json.Marshal(storedPals)
+		if err != nil {
+			return nil, err
+		}
+		species[key] = updated
+	}
+	if assigned == 0 {
+		return data, nil
+	}
+
+	fmt.Println("Assigned new IDs to", assigned, "stored pals")
+	return json.Marshal(palStore)
+}
+
+// fieldKey returns the key of field in object, matched ignoring case like encoding/json does
+func fieldKey(object map[string]json.RawMessage, field string) string {
+	for key := range object {
+		if strings.EqualFold(key, field) {
+			return key
+		}
+	}
+	return field
+}
+
+// parseEnvelope splits a data file into its schema version and data. Files written
+// before the envelope existed hold the data directly and are version 1.
+func parseEnvelope(content []byte) (dataEnvelope, error) {
+	var fields map[string]json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return dataEnvelope{}, err
+		}
+	}
+	if _, ok := fields["schema_version"]; !ok {
+		return dataEnvelope{SchemaVersion: 1, Data: content}, nil
+	}
+
+	var envelope dataEnvelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return dataEnvelope{}, err
+	}
+	if envelope.SchemaVersion < 1 {
+		return dataEnvelope{}, fmt.Errorf("invalid schema version %d", envelope.SchemaVersion)
+	}
+	return envelope, nil
+}
+
+// migrateEnvelope runs every migration newer than the version of envelope
+func migrateEnvelope(kind string, envelope dataEnvelope) (dataEnvelope, error) {
+	if envelope.SchemaVersion > SchemaVersion {
+		return envelope, fmt.Errorf("schema version %d is newer than the supported version %d", envelope.SchemaVersion, SchemaVersion)
+	}
+
+	for _, migration := range schemaMigrations {
+		if migration.version <= envelope.SchemaVersion {
+			continue
+		}
+		data, err := migration.migrate(kind, envelope.Data)
+		if err != nil {
+			return envelope, fmt.Errorf("migration to schema version %d (%s): %w", migration.version, migration.description, err)
+		}
+		envelope = dataEnvelope{SchemaVersion: migration.version, Data: data}
+	}
+
+	return envelope, nil
+}
+
+// backupBeforeUpgrade keeps a copy of the file at path as it was before the upgrade
+// from version. An existing copy is kept, so it always holds the original file.
+func backupBeforeUpgrade(path string, content []byte, version int) error {
+	backupPath := fmt.Sprintf("%s.schema-v%d.bak", path, version)
+	file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,100 @@
+package datamanage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// legacyStoredPals is stored_pals.json as written before the envelope and unique IDs
+const legacyStoredPals = `[
+  {"Name": "Lamball", "StoredPals": [{"ID": 1, "Gender": "f", "PassiveSkills": ["Artisan"]}, {"ID": 2, "Gender": "m"}]},
+  {"Name": "Bushi", "StoredPals": [{"ID": 1, "Gender": "m"}]}
+]`
+
+func TestLegacyStoredPalsAreUpgraded(t *testing.T) {
+	cfg := testConfig(t.TempDir(), StoreBackendJSON)
+	path := filepath.Join(cfg.DataDir, cfg.StoredPalsFile)
+	if err := os.WriteFile(path, []byte(legacyStoredPals), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	service := newTestService(t, cfg)
+
+	palStore, err := service.ReadStoredPals(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, pal := range speciesPals(palStore) {
+		if pal.Pal.ID == "" || seen[pal.Pal.ID] {
+			t.Fatalf("ID %q is empty or used twice", pal.Pal.ID)
+		}
+		seen[pal.Pal.ID] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("got %d stored pals, want 3", len(seen))
+	}
+	if palStore[0].StoredPals[0].PassiveSkills[0] != "Artisan" {
+		t.Errorf("passive skills were lost: %+v", palStore[0].StoredPals[0])
+	}
+
+	backup, err := os.ReadFile(path + ".schema-v1.bak")
+	if err != nil {
+		t.Fatalf("expected a copy of the original file: %v", err)
+	}
+	if string(backup) != legacyStoredPals {
+		t.Errorf("the copy differs from the original file")
+	}
+
+	// Reading again keeps the assigned IDs and leaves the file alone
+	upgraded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if _, err := service.ReadStoredPals(DefaultProfile); err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	again, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(upgraded, again) {
+		t.Errorf("the upgraded file changed when read again")
+	}
+}
+
+func TestMigrationsAreIdempotent(t *testing.T) {
+	upgraded, err := migrateEnvelope(dataKindStoredPals, dataEnvelope{SchemaVersion: 1, Data: []byte(legacyStoredPals)})
+	if err != nil {
+		t.Fatalf("migrateEnvelope: %v", err)
+	}
+	if upgraded.SchemaVersion != SchemaVersion {
+		t.Fatalf("got schema version %d, want %d", upgraded.SchemaVersion, SchemaVersion)
+	}
+
+	// Running every migration again on upgraded data must not change it
+	again, err := migrateEnvelope(dataKindStoredPals, dataEnvelope{SchemaVersion: 1, Data: upgraded.Data})
+	if err != nil {
+		t.Fatalf("migrateEnvelope: %v", err)
+	}
+	if !bytes.Equal(upgraded.Data, again.Data) {
+		t.Errorf("migrating upgraded data changed it:\n%s\n%s", upgraded.Data, again.Data)
+	}
+}
+
+func TestNewerSchemaVersionIsRejected(t *testing.T) {
+	cfg := testConfig(t.TempDir(), StoreBackendJSON)
+	newer := []byte(`{"schema_version": 99, "data": []}`)
+	if err := os.WriteFile(filepath.Join(cfg.DataDir, cfg.StoredPalsFile), newer, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if _, err := service.ReadStoredPals(DefaultProfile); err == nil {
+		t.Fatalf("ReadStoredPals read a file with a newer schema version")
+	}
+}
@@ -0,0 +1,60 @@
+package datamanage
+
+import (
+	"os"
+	"palworld_tools/config"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Service reads and writes the data in one data directory through its store.
+// Build it once with NewService and pass it to everything that needs the data.
+type Service struct {
+	store           Store
+	dataDir         string
+	backupRetention int
+	maxPalLevel     int
+
+	// storeMutex serializes read-modify-write cycles on the pal stores so
+	// concurrent requests can't overwrite each other's changes
+	storeMutex sync.Mutex
+
+	// cache is read without locking, so readers never wait for a reload
+	cache       atomic.Pointer[dataCache]
+	reloadMutex sync.Mutex
+}
+
+// NewService opens the configured store in the configured data directory,
+// creating the directory if needed
+func NewService(cfg *config.Config) (*Service, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := DataFiles{
+		Dir:                cfg.DataDir,
+		Pals:               cfg.PalsFile,
+		StoredPals:         cfg.StoredPalsFile,
+		PassiveSkills:      cfg.PassiveSkillsFile,
+		PassiveSkillCombos: cfg.PassiveSkillCombosFile,
+	}
+	store, err := OpenStore(cfg.StoreBackend, files, filepath.Join(cfg.DataDir, cfg.SQLiteFile))
+	if err != nil {
+		return nil, err
+	}
+
+	service := &Service{
+		store:           store,
+		dataDir:         cfg.DataDir,
+		backupRetention: cfg.BackupRetention,
+		maxPalLevel:     cfg.MaxPalLevel,
+	}
+	if service.maxPalLevel <= 0 {
+		service.maxPalLevel = DefaultMaxPalLevel
+	}
+	if err := service.recoverStoredPals(); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
@@ -0,0 +1,126 @@
+package datamanage
+
+import (
+	"os"
+	"palworld_tools/config"
+	"palworld_tools/models"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig returns the default file names in dir with the given backend
+func testConfig(dir string, backend string) *config.Config {
+	return &config.Config{
+		DataDir:                dir,
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           backend,
+		SQLiteFile:             "palworld.db",
+		BackupRetention:        DefaultBackupRetention,
+	}
+}
+
+// newTestService opens a service on cfg and fills it with a small paldex and passive skill list
+func newTestService(t *testing.T, cfg *config.Config) *Service {
+	t.Helper()
+
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	pals := []models.Pal{{Name: "Lamball"}, {Name: "Cattiva"}, {Name: "Bushi"}}
+	if err := service.WritePaldex(pals); err != nil {
+		t.Fatalf("WritePaldex: %v", err)
+	}
+	passiveSkills := []models.PassiveSkill{{Name: "Artisan", Tier: 3}, {Name: "Serious", Tier: 1}}
+	if err := service.WritePassiveSkills(passiveSkills); err != nil {
+		t.Fatalf("WritePassiveSkills: %v", err)
+	}
+	return service
+}
+
+func countStoredPals(t *testing.T, service *Service, profile string) int {
+	t.Helper()
+
+	palStore, err := service.ReadStoredPals(profile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	return len(speciesPals(palStore))
+}
+
+func TestServiceUsesConfiguredFiles(t *testing.T) {
+	cfg := testConfig(filepath.Join(t.TempDir(), "nested", "data"), StoreBackendJSON)
+	cfg.PalsFile = "paldex.json"
+	cfg.StoredPalsFile = "my_pals.json"
+	service := newTestService(t, cfg)
+
+	if _, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m"}, SourceCLI); err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+
+	for _, name := range []string{"paldex.json", "my_pals.json", "passive_skills.json"} {
+		if _, err := os.Stat(filepath.Join(cfg.DataDir, name)); err != nil {
+			t.Errorf("expected %s in the data directory: %v", name, err)
+		}
+	}
+	for _, name := range []string{"pals.json", "stored_pals.json"} {
+		if _, err := os.Stat(filepath.Join(cfg.DataDir, name)); err == nil {
+			t.Errorf("%s was written although another name is configured", name)
+		}
+	}
+}
+
+func TestServicesDontShareState(t *testing.T) {
+	first := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+	second := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+	if err := second.WritePaldex([]models.Pal{{Name: "Anubis"}}); err != nil {
+		t.Fatalf("WritePaldex: %v", err)
+	}
+
+	if _, err := first.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "f"}, SourceAPI); err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	if got := countStoredPals(t, second, DefaultProfile); got != 0 {
+		t.Errorf("second service has %d stored pals, want 0", got)
+	}
+
+	// Each service caches its own paldex
+	if pal, _ := first.FindPal("Anubis"); pal != nil {
+		t.Errorf("first service found Anubis from the second service's paldex")
+	}
+	if pal, _ := second.FindPal("Lamball"); pal != nil {
+		t.Errorf("second service found Lamball from the first service's paldex")
+	}
+}
+
+func TestSQLiteServiceKeepsStoreAcrossRestarts(t *testing.T) {
+	cfg := testConfig(t.TempDir(), StoreBackendSQLite)
+	service := newTestService(t, cfg)
+
+	id, err := service.AddPal(DefaultProfile, "Cattiva", models.StoredPal{Gender: "m", PassiveSkills: []string{"artisan"}}, SourceAPI)
+	if err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.DataDir, cfg.SQLiteFile)); err != nil {
+		t.Fatalf("expected the database in the data directory: %v", err)
+	}
+
+	reopened, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	palStore, err := reopened.ReadStoredPals(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	if len(palStore) != 1 || len(palStore[0].StoredPals) != 1 {
+		t.Fatalf("got %+v, want a single stored Cattiva", palStore)
+	}
+	pal := palStore[0].StoredPals[0]
+	if pal.ID != id || len(pal.PassiveSkills) != 1 || pal.PassiveSkills[0] != "Artisan" {
+		t.Errorf("got %+v, want ID %s with passive Artisan", pal, id)
+	}
+}
@@ -0,0 +1,520 @@
+package datamanage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"palworld_tools/models"
+	"strconv"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS pals (
+	position INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL,
+	data     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS passive_skills (
+	position INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL,
+	effect   TEXT NOT NULL,
+	tier     INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS passive_skill_combos (
+	position INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL,
+	skills   TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS profiles (
+	name TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS stored_pals (
+	profile          TEXT NOT NULL,
+	species_position INTEGER NOT NULL,
+	species          TEXT NOT NULL,
+	position         INTEGER NOT NULL,
+	data             TEXT NOT NULL,
+	PRIMARY KEY (profile, species_position, position)
+);
+CREATE TABLE IF NOT EXISTS store_events (
+	profile TEXT NOT NULL,
+	id      INTEGER NOT NULL,
+	data    TEXT NOT NULL,
+	PRIMARY KEY (profile, id)
+);
+`
+
+// profileTables are the tables holding data of a single profile
+var profileTables = []string{"stored_pals", "store_events"}
+
+// metaJSONMigrated is the key of the meta table marking a database that already
+// imported the JSON data files
+const metaJSONMigrated = "json_migrated"
+
+// SQLiteStore keeps all data in a single embedded SQLite database.
+// Nested values such as suitability, children and passives are stored as JSON.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the database at path. A new database
+// imports the existing JSON data files from source once so current stores carry over.
+func NewSQLiteStore(path string, source *JSONStore) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so share one connection
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.createSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.migrateFromJSON(source); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// createSchema creates the tables that don't exist yet
+func (s *SQLiteStore) createSchema() error {
+	_, err := s.db.Exec(sqliteSchema)
+	return err
+}
+
+// migrateFromJSON copies every JSON data file into the database the first time it is opened.
+// The copy and the marker are written in one transaction, so a failed migration leaves
+// the database empty and is run again on the next start.
+func (s *SQLiteStore) migrateFromJSON(source *JSONStore) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		var value string
+		err := tx.QueryRow(`SELECT value FROM meta WHERE key = ?`, metaJSONMigrated).Scan(&value)
+		if err == nil {
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		fmt.Println("Migrating JSON data files into SQLite")
+
+		pals, err := source.ReadPaldex()
+		if err != nil {
+			return err
+		}
+		if err := writePaldex(tx, pals); err != nil {
+			return err
+		}
+
+		passiveSkills, err := source.ReadPassiveSkills()
+		if err != nil {
+			return err
+		}
+		if err := writePassiveSkills(tx, passiveSkills); err != nil {
+			return err
+		}
+
+		combos, err := source.ReadPassiveSkillCombos()
+		if err != nil {
+			return err
+		}
+		if err := writePassiveSkillCombos(tx, combos); err != nil {
+			return err
+		}
+
+		profiles, err := source.ListProfiles()
+		if err != nil {
+			return err
+		}
+		for _, profile := range profiles {
+			if profile != DefaultProfile {
+				if err := insertProfile(tx, profile); err != nil {
+					return err
+				}
+			}
+
+			palStore, err := source.ReadStoredPals(profile)
+			if err != nil {
+				return err
+			}
+			if err := writeStoredPals(tx, profile, palStore); err != nil {
+				return err
+			}
+
+			events, err := source.ReadStoreEvents(profile)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if err := insertStoreEvent(tx, profile, event); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)`, metaJSONMigrated, "true")
+		return err
+	})
+}
+
+// sqlExecer runs statements on the database or inside a transaction
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) ReadPaldex() ([]models.Pal, error) {
+	rows, err := s.db.Query(`SELECT data FROM pals ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pals []models.Pal
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var pal models.Pal
+		if err := json.Unmarshal([]byte(data), &pal); err != nil {
+			return nil, err
+		}
+		pals = append(pals, pal)
+	}
+
+	return pals, rows.Err()
+}
+
+func (s *SQLiteStore) WritePaldex(pals []models.Pal) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		return writePaldex(tx, pals)
+	})
+}
+
+func writePaldex(tx *sql.Tx, pals []models.Pal) error {
+	return replaceRows(tx, "pals", func() error {
+		for i, pal := range pals {
+			data, err := json.Marshal(pal)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO pals (position, name, data) VALUES (?, ?, ?)`, i, pal.Name, string(data))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ReadPassiveSkills() ([]models.PassiveSkill, error) {
+	rows, err := s.db.Query(`SELECT name, effect, tier FROM passive_skills ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var passiveSkills []models.PassiveSkill
+	for rows.Next() {
+		var skill models.PassiveSkill
+		if err := rows.Scan(&skill.Name, &skill.Effect, &skill.Tier); err != nil {
+			return nil, err
+		}
+		passiveSkills = append(passiveSkills, skill)
+	}
+
+	return passiveSkills, rows.Err()
+}
+
+func (s *SQLiteStore) WritePassiveSkills(passiveSkills []models.PassiveSkill) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		return writePassiveSkills(tx, passiveSkills)
+	})
+}
+
+func writePassiveSkills(tx *sql.Tx, passiveSkills []models.PassiveSkill) error {
+	return replaceRows(tx, "passive_skills", func() error {
+		for i, skill := range passiveSkills {
+			_, err := tx.Exec(`INSERT INTO passive_skills (position, name, effect, tier) VALUES (?, ?, ?, ?)`,
+				i, skill.Name, skill.Effect, skill.Tier)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ReadPassiveSkillCombos() ([]models.PassiveSkillCombo, error) {
+	rows, err := s.db.Query(`SELECT name, skills FROM passive_skill_combos ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var combos []models.PassiveSkillCombo
+	for rows.Next() {
+		var combo models.PassiveSkillCombo
+		var skills string
+		if err := rows.Scan(&combo.Name, &skills); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(skills), &combo.Skills); err != nil {
+			return nil, err
+		}
+		combos = append(combos, combo)
+	}
+
+	return combos, rows.Err()
+}
+
+func (s *SQLiteStore) WritePassiveSkillCombos(combos []models.PassiveSkillCombo) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		return writePassiveSkillCombos(tx, combos)
+	})
+}
+
+func writePassiveSkillCombos(tx *sql.Tx, combos []models.PassiveSkillCombo) error {
+	return replaceRows(tx, "passive_skill_combos", func() error {
+		for i, combo := range combos {
+			skills, err := json.Marshal(combo.Skills)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO passive_skill_combos (position, name, skills) VALUES (?, ?, ?)`,
+				i, combo.Name, string(skills))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ReadStoredPals(profile string) ([]models.PalSpecies, error) {
+	rows, err := s.db.Query(`SELECT species_position, species, data FROM stored_pals WHERE profile = ? ORDER BY species_position, position`, profile)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var palStore []models.PalSpecies
+	lastPosition := -1
+	for rows.Next() {
+		var position int
+		var species, data string
+		if err := rows.Scan(&position, &species, &data); err != nil {
+			return nil, err
+		}
+		var pal models.StoredPal
+		if err := json.Unmarshal([]byte(data), &pal); err != nil {
+			return nil, err
+		}
+
+		if position != lastPosition {
+			palStore = append(palStore, models.PalSpecies{Name: species})
+			lastPosition = position
+		}
+		last := &palStore[len(palStore)-1]
+		last.StoredPals = append(last.StoredPals, pal)
+	}
+
+	return palStore, rows.Err()
+}
+
+func (s *SQLiteStore) WriteStoredPals(profile string, palStore []models.PalSpecies) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		return writeStoredPals(tx, profile, palStore)
+	})
+}
+
+func writeStoredPals(tx *sql.Tx, profile string, palStore []models.PalSpecies) error {
+	return replaceProfileRows(tx, "stored_pals", profile, func() error {
+		for i, species := range palStore {
+			for j, pal := range species.StoredPals {
+				data, err := json.Marshal(pal)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(`INSERT INTO stored_pals (profile, species_position, species, position, data) VALUES (?, ?, ?, ?, ?)`,
+					profile, i, species.Name, j, string(data))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ReadStoreEvents(profile string) ([]models.StoreEvent, error) {
+	rows, err := s.db.Query(`SELECT data FROM store_events WHERE profile = ? ORDER BY id`, profile)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.StoreEvent
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var event models.StoreEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *SQLiteStore) AppendStoreEvent(profile string, event models.StoreEvent) error {
+	return insertStoreEvent(s.db, profile, event)
+}
+
+func insertStoreEvent(db sqlExecer, profile string, event models.StoreEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO store_events (profile, id, data) VALUES (?, ?, ?)`, profile, event.ID, string(data))
+	return err
+}
+
+func (s *SQLiteStore) LastStoreEvent(profile string) (*models.StoreEvent, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM store_events WHERE profile = ? ORDER BY id DESC LIMIT 1`, profile).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var event models.StoreEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (s *SQLiteStore) RemoveLastStoreEvent(profile string, id int) error {
+	_, err := s.db.Exec(`DELETE FROM store_events WHERE profile = ? AND id = ? AND id = (SELECT MAX(id) FROM store_events WHERE profile = ?)`,
+		profile, id, profile)
+	return err
+}
+
+// DataVersion returns SQLite's data_version, which changes when another connection,
+// such as another process, commits to the database
+func (s *SQLiteStore) DataVersion() (string, error) {
+	var version int64
+	if err := s.db.QueryRow(`PRAGMA data_version`).Scan(&version); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(version, 10), nil
+}
+
+// ListProfiles returns the default profile followed by the created profiles by name
+func (s *SQLiteStore) ListProfiles() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM profiles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []string{DefaultProfile}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, name)
+	}
+
+	return profiles, rows.Err()
+}
+
+func (s *SQLiteStore) CreateProfile(name string) error {
+	return insertProfile(s.db, name)
+}
+
+func insertProfile(db sqlExecer, name string) error {
+	_, err := db.Exec(`INSERT INTO profiles (name) VALUES (?)`, name)
+	return err
+}
+
+func (s *SQLiteStore) RenameProfile(name string, newName string) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE profiles SET name = ? WHERE name = ?`, newName, name)
+		if err != nil {
+			return err
+		}
+		for _, table := range profileTables {
+			if _, err := tx.Exec(`UPDATE `+table+` SET profile = ? WHERE profile = ?`, newName, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) DeleteProfile(name string) error {
+	return s.inTransaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM profiles WHERE name = ?`, name); err != nil {
+			return err
+		}
+		for _, table := range profileTables {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE profile = ?`, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceRows empties table and refills it with insert inside tx
+func replaceRows(tx *sql.Tx, table string, insert func() error) error {
+	if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+		return err
+	}
+	return insert()
+}
+
+// replaceProfileRows empties the rows of profile in table and refills them with insert inside tx
+func replaceProfileRows(tx *sql.Tx, table string, profile string, insert func() error) error {
+	if _, err := tx.Exec(`DELETE FROM `+table+` WHERE profile = ?`, profile); err != nil {
+		return err
+	}
+	return insert()
+}
+
+// inTransaction runs update inside a single transaction
+func (s *SQLiteStore) inTransaction(update func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := update(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
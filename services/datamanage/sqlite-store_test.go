@@ -0,0 +1,52 @@
+package datamanage
+
+import (
+	"os"
+	"palworld_tools/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestFailedJSONMigrationRunsAgain(t *testing.T) {
+	dir := t.TempDir()
+	jsonService := newTestService(t, testConfig(dir, StoreBackendJSON))
+	if err := jsonService.CreateProfile("alt"); err != nil {
+		t.Fatalf("CreateProfile: %v", err)
+	}
+	if _, err := jsonService.AddPal("alt", "Bushi", models.StoredPal{Gender: "f"}, SourceAPI); err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+
+	// A broken journal makes the migration fail after the profile was copied
+	journal := filepath.Join(dir, "profiles", "alt", "store_journal.jsonl")
+	content, err := os.ReadFile(journal)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := os.WriteFile(journal, []byte("{broken\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cfg := testConfig(dir, StoreBackendSQLite)
+	if _, err := NewService(cfg); err == nil {
+		t.Fatalf("NewService succeeded with a broken journal")
+	}
+
+	if err := os.WriteFile(journal, content, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService after fixing the journal: %v", err)
+	}
+	if got := countStoredPals(t, service, "alt"); got != 1 {
+		t.Errorf("got %d stored pals in profile alt, want 1", got)
+	}
+	history, err := service.StoreHistory("alt", 0)
+	if err != nil {
+		t.Fatalf("StoreHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("got %d journal events in profile alt, want 1", len(history))
+	}
+}
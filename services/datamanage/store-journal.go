@@ -0,0 +1,366 @@
+package datamanage
+
+import (
+	"errors"
+	"fmt"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"time"
+)
+
+// Sources of a change to the pal store
+const (
+	SourceAPI    = "api"
+	SourceCLI    = "cli"
+	SourceImport = "import"
+)
+
+// Actions recorded in the journal
+const (
+	ActionAdd     = "add"
+	ActionUpdate  = "update"
+	ActionRemove  = "remove"
+	ActionImport  = "import"
+	ActionRestore = "restore"
+	ActionUndo    = "undo"
+	ActionRedo    = "redo"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// commitStoredPals applies update to the pal store of a profile, appends the changed pals
+// to the journal and then writes the store. The caller must hold storeMutex. No event is
+// recorded when nothing changed. The journal is written first, so a change that was
+// journaled but never reached the store because the process stopped is reapplied by
+// recoverStoredPals. When writing the store fails the event is removed again.
+func (s *Service) commitStoredPals(profile string, action string, source string, target int, update func(palStore []models.PalSpecies) ([]models.PalSpecies, error)) (*models.StoreEvent, error) {
+	palStore, err := s.ReadStoredPals(profile)
+	if err != nil {
+		return nil, err
+	}
+	before := speciesPals(palStore)
+
+	palStore, err = update(palStore)
+	if err != nil {
+		return nil, err
+	}
+
+	var event *models.StoreEvent
+	if changes := diffSpeciesPals(before, speciesPals(palStore)); len(changes) > 0 {
+		event, err = s.appendStoreEvent(profile, models.StoreEvent{
+			Time:    time.Now(),
+			Action:  action,
+			Source:  source,
+			Target:  target,
+			Changes: changes,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.WriteStoredPals(profile, palStore); err != nil {
+		if event != nil {
+			if removeErr := s.store.RemoveLastStoreEvent(profile, event.ID); removeErr != nil {
+				return nil, errors.Join(err, removeErr)
+			}
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// appendStoreEvent numbers event after the latest event of the journal and appends it
+func (s *Service) appendStoreEvent(profile string, event models.StoreEvent) (*models.StoreEvent, error) {
+	last, err := s.store.LastStoreEvent(profile)
+	if err != nil {
+		return nil, err
+	}
+	event.ID = 1
+	if last != nil {
+		event.ID = last.ID + 1
+	}
+	if err := s.store.AppendStoreEvent(profile, event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// recoverStoredPals reapplies the latest journal event of every profile whose pal store
+// doesn't reflect it yet, which happens when the process stopped between the two writes
+func (s *Service) recoverStoredPals() error {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	profiles, err := s.store.ListProfiles()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		event, err := s.store.LastStoreEvent(profile)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			continue
+		}
+		palStore, err := s.store.ReadStoredPals(profile)
+		if err != nil {
+			return err
+		}
+		if changesApplied(palStore, event.Changes) {
+			continue
+		}
+
+		fmt.Println("Reapplying journal event", event.ID, "to the pal store of profile", profile)
+		if err := s.store.WriteStoredPals(profile, applyChanges(palStore, event.Changes, false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changesApplied reports whether every changed pal is in its state after the change
+func changesApplied(palStore []models.PalSpecies, changes []models.PalChange) bool {
+	for _, change := range changes {
+		i, j, found := findStoredPal(palStore, change.PalID)
+		if change.After == nil {
+			if found {
+				return false
+			}
+			continue
+		}
+		if !found || !sameSpeciesPal(models.SpeciesPal{Species: palStore[i].Name, Pal: palStore[i].StoredPals[j]}, *change.After) {
+			return false
+		}
+	}
+	return true
+}
+
+// speciesPals flattens the store into its pals in store order
+func speciesPals(palStore []models.PalSpecies) []models.SpeciesPal {
+	pals := make([]models.SpeciesPal, 0)
+	for _, species := range palStore {
+		for _, pal := range species.StoredPals {
+			pals = append(pals, models.SpeciesPal{Species: species.Name, Pal: pal})
+		}
+	}
+	return pals
+}
+
+// diffSpeciesPals lists the pals that were removed, changed or added, by ID
+func diffSpeciesPals(before []models.SpeciesPal, after []models.SpeciesPal) []models.PalChange {
+	afterByID := make(map[string]models.SpeciesPal)
+	for _, pal := range after {
+		afterByID[pal.Pal.ID] = pal
+	}
+
+	changes := make([]models.PalChange, 0)
+	seen := make(map[string]bool)
+	for _, old := range before {
+		seen[old.Pal.ID] = true
+		current, ok := afterByID[old.Pal.ID]
+		if !ok {
+			changes = append(changes, models.PalChange{PalID: old.Pal.ID, Before: &old})
+			continue
+		}
+		if !sameSpeciesPal(old, current) {
+			changes = append(changes, models.PalChange{PalID: old.Pal.ID, Before: &old, After: &current})
+		}
+	}
+	for _, current := range after {
+		if !seen[current.Pal.ID] {
+			changes = append(changes, models.PalChange{PalID: current.Pal.ID, After: &current})
+		}
+	}
+
+	return changes
+}
+
+func sameSpeciesPal(a models.SpeciesPal, b models.SpeciesPal) bool {
+	return a.Species == b.Species &&
+		a.Pal.Gender == b.Pal.Gender &&
+		a.Pal.Nickname == b.Pal.Nickname &&
+		a.Pal.Level == b.Pal.Level &&
+		a.Pal.Talents == b.Pal.Talents &&
+		a.Pal.CondensationRank == b.Pal.CondensationRank &&
+		a.Pal.Container == b.Pal.Container &&
+		sameStrings(a.Pal.PassiveSkills, b.Pal.PassiveSkills) &&
+		sameStrings(a.Pal.ActiveSkills, b.Pal.ActiveSkills)
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// undoStacks replays the journal into the events that can be undone, most recent
+// last, and the events that can be redone, most recently undone last.
+// A new change clears the redo stack.
+func undoStacks(events []models.StoreEvent) ([]models.StoreEvent, []models.StoreEvent) {
+	byID := make(map[int]models.StoreEvent)
+	applied := make([]models.StoreEvent, 0)
+	undone := make([]models.StoreEvent, 0)
+	for _, event := range events {
+		byID[event.ID] = event
+		switch event.Action {
+		case ActionUndo:
+			if len(applied) > 0 && applied[len(applied)-1].ID == event.Target {
+				applied = applied[:len(applied)-1]
+				undone = append(undone, byID[event.Target])
+			}
+		case ActionRedo:
+			if len(undone) > 0 && undone[len(undone)-1].ID == event.Target {
+				undone = undone[:len(undone)-1]
+				applied = append(applied, byID[event.Target])
+			}
+		default:
+			applied = append(applied, event)
+			undone = undone[:0]
+		}
+	}
+	return applied, undone
+}
+
+// Undo reverts the most recent change to the pal store of a profile that is not undone yet
+func (s *Service) Undo(profile string, source string) (*dto.StoreEvent, error) {
+	return s.replayEvent(profile, ActionUndo, source)
+}
+
+// Redo reapplies the most recently undone change
+func (s *Service) Redo(profile string, source string) (*dto.StoreEvent, error) {
+	return s.replayEvent(profile, ActionRedo, source)
+}
+
+func (s *Service) replayEvent(profile string, action string, source string) (*dto.StoreEvent, error) {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	if err := s.requireProfile(profile); err != nil {
+		return nil, err
+	}
+	events, err := s.store.ReadStoreEvents(profile)
+	if err != nil {
+		return nil, err
+	}
+	applied, undone := undoStacks(events)
+
+	var target models.StoreEvent
+	if action == ActionUndo {
+		if len(applied) == 0 {
+			return nil, ErrNothingToUndo
+		}
+		target = applied[len(applied)-1]
+	} else {
+		if len(undone) == 0 {
+			return nil, ErrNothingToRedo
+		}
+		target = undone[len(undone)-1]
+	}
+
+	event, err := s.commitStoredPals(profile, action, source, target.ID, func(palStore []models.PalSpecies) ([]models.PalSpecies, error) {
+		return applyChanges(palStore, target.Changes, action == ActionUndo), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		// The store already matches, record the step anyway so the stacks move on
+		event, err = s.appendStoreEvent(profile, models.StoreEvent{
+			Time:   time.Now(),
+			Action: action,
+			Source: source,
+			Target: target.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := storeEventDTO(*event, false)
+	return &result, nil
+}
+
+// applyChanges puts every changed pal in its state before (undo) or after (redo) the change
+func applyChanges(palStore []models.PalSpecies, changes []models.PalChange, undo bool) []models.PalSpecies {
+	for k := range changes {
+		change := changes[k]
+		state := change.After
+		if undo {
+			// Revert in reverse order
+			change = changes[len(changes)-1-k]
+			state = change.Before
+		}
+
+		if i, j, ok := findStoredPal(palStore, change.PalID); ok {
+			palStore[i].StoredPals = append(palStore[i].StoredPals[:j], palStore[i].StoredPals[j+1:]...)
+			if len(palStore[i].StoredPals) == 0 {
+				palStore = append(palStore[:i], palStore[i+1:]...)
+			}
+		}
+		if state != nil {
+			palStore = addToSpecies(palStore, state.Species, state.Pal)
+		}
+	}
+	return palStore
+}
+
+// StoreHistory returns the journal of a profile, most recent event first. A limit of 0 returns every event.
+func (s *Service) StoreHistory(profile string, limit int) ([]dto.StoreEvent, error) {
+	if err := s.requireProfile(profile); err != nil {
+		return nil, err
+	}
+	events, err := s.store.ReadStoreEvents(profile)
+	if err != nil {
+		return nil, err
+	}
+	_, undone := undoStacks(events)
+	isUndone := make(map[int]bool)
+	for _, event := range undone {
+		isUndone[event.ID] = true
+	}
+
+	history := make([]dto.StoreEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		if limit > 0 && len(history) == limit {
+			break
+		}
+		history = append(history, storeEventDTO(events[i], isUndone[events[i].ID]))
+	}
+	return history, nil
+}
+
+func storeEventDTO(event models.StoreEvent, undone bool) dto.StoreEvent {
+	result := dto.StoreEvent{
+		Id:      event.ID,
+		Time:    event.Time,
+		Action:  event.Action,
+		Source:  event.Source,
+		Target:  event.Target,
+		Undone:  undone,
+		Changes: make([]dto.PalChange, 0, len(event.Changes)),
+	}
+	for _, change := range event.Changes {
+		palChange := dto.PalChange{PalId: change.PalID}
+		if change.Before != nil {
+			record := palRecord(change.Before.Species, change.Before.Pal)
+			palChange.Before = &record
+		}
+		if change.After != nil {
+			record := palRecord(change.After.Species, change.After.Pal)
+			palChange.After = &record
+		}
+		result.Changes = append(result.Changes, palChange)
+	}
+	return result
+}
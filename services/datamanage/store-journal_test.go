@@ -0,0 +1,125 @@
+package datamanage
+
+import (
+	"errors"
+	"palworld_tools/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastStoreEventReadsLongLines(t *testing.T) {
+	for _, backend := range []string{StoreBackendJSON, StoreBackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			service := newTestService(t, testConfig(t.TempDir(), backend))
+
+			// Nicknames long enough that each event spans several read chunks
+			for i := 0; i < 3; i++ {
+				nickname := strings.Repeat(string(rune('a'+i)), 10000)
+				if _, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m", Nickname: nickname}, SourceAPI); err != nil {
+					t.Fatalf("AddPal: %v", err)
+				}
+			}
+
+			event, err := service.store.LastStoreEvent(DefaultProfile)
+			if err != nil {
+				t.Fatalf("LastStoreEvent: %v", err)
+			}
+			if event == nil || event.ID != 3 || !strings.HasPrefix(event.Changes[0].After.Pal.Nickname, "c") {
+				t.Fatalf("got %+v, want event 3 adding the third pal", event)
+			}
+		})
+	}
+}
+
+func TestRecoverStoredPalsReappliesJournaledChange(t *testing.T) {
+	for _, backend := range []string{StoreBackendJSON, StoreBackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := testConfig(t.TempDir(), backend)
+			service := newTestService(t, cfg)
+
+			id, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m"}, SourceAPI)
+			if err != nil {
+				t.Fatalf("AddPal: %v", err)
+			}
+
+			// Journal a removal without writing the store, as if the process stopped in between
+			before := models.SpeciesPal{Species: "Lamball", Pal: models.StoredPal{ID: id, Gender: "m"}}
+			_, err = service.appendStoreEvent(DefaultProfile, models.StoreEvent{
+				Time:    time.Now(),
+				Action:  ActionRemove,
+				Source:  SourceAPI,
+				Changes: []models.PalChange{{PalID: id, Before: &before}},
+			})
+			if err != nil {
+				t.Fatalf("appendStoreEvent: %v", err)
+			}
+			if got := countStoredPals(t, service, DefaultProfile); got != 1 {
+				t.Fatalf("got %d stored pals before recovery, want 1", got)
+			}
+
+			reopened, err := NewService(cfg)
+			if err != nil {
+				t.Fatalf("NewService: %v", err)
+			}
+			if got := countStoredPals(t, reopened, DefaultProfile); got != 0 {
+				t.Errorf("got %d stored pals after recovery, want 0", got)
+			}
+
+			// The recovered removal can be undone like any other
+			if _, err := reopened.Undo(DefaultProfile, SourceAPI); err != nil {
+				t.Fatalf("Undo: %v", err)
+			}
+			if got := countStoredPals(t, reopened, DefaultProfile); got != 1 {
+				t.Errorf("got %d stored pals after undo, want 1", got)
+			}
+		})
+	}
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriteStore is a store whose pal store writes fail
+type failingWriteStore struct {
+	Store
+}
+
+func (s failingWriteStore) WriteStoredPals(profile string, palStore []models.PalSpecies) error {
+	return errWriteFailed
+}
+
+func TestFailedStoreWriteIsNotJournaled(t *testing.T) {
+	for _, backend := range []string{StoreBackendJSON, StoreBackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := testConfig(t.TempDir(), backend)
+			service := newTestService(t, cfg)
+
+			if _, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m"}, SourceAPI); err != nil {
+				t.Fatalf("AddPal: %v", err)
+			}
+			store := service.store
+			service.store = failingWriteStore{Store: store}
+			if _, err := service.AddPal(DefaultProfile, "Bushi", models.StoredPal{Gender: "f"}, SourceAPI); !errors.Is(err, errWriteFailed) {
+				t.Fatalf("AddPal with a failing store: got %v, want the write error", err)
+			}
+			service.store = store
+
+			events, err := store.ReadStoreEvents(DefaultProfile)
+			if err != nil {
+				t.Fatalf("ReadStoreEvents: %v", err)
+			}
+			if len(events) != 1 {
+				t.Errorf("got %d journal events, want only the successful add", len(events))
+			}
+
+			// Nothing is reapplied on the next start
+			reopened, err := NewService(cfg)
+			if err != nil {
+				t.Fatalf("NewService: %v", err)
+			}
+			if got := countStoredPals(t, reopened, DefaultProfile); got != 1 {
+				t.Errorf("got %d stored pals after a restart, want 1", got)
+			}
+		})
+	}
+}
@@ -1,97 +1,203 @@
 package datamanage
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"palworld_tools/models"
+	"strconv"
+	"strings"
 )
 
-func AddPal(palName string, palGender string, passiveSkill []string) error {
+const (
+	DefaultMaxPalLevel  = 65
+	maxTalent           = 100
+	maxCondensationRank = 4
+	maxPassiveSkills    = 4
 
-	fmt.Println("Reading paldex and passive skills")
-	pals, err := ReadPaldex()
-	if err != nil {
-		return err
-	}
-	passiveSkills, err := ReadPassiveSkills()
-	if err != nil {
-		return err
-	}
+	ContainerPalbox = "palbox"
+	ContainerParty  = "party"
+	ContainerBase   = "base"
+)
+
+// ValidationError reports a pal that can't be stored as given
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalidPal(format string, args ...any) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// AddPal stores a new pal of the given species in the store of profile and returns its ID.
+// The ID of pal is ignored, a new one is assigned. source is recorded in the journal.
+func (s *Service) AddPal(profile string, palName string, pal models.StoredPal, source string) (string, error) {
 
 	fmt.Println("Validate pal name")
 	// validate pal name
-	pal := models.FindPal(pals, palName)
-	if pal == nil {
-		return fmt.Errorf("pal name not found")
+	palName, err := s.validatePalName(palName)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Println("Validate pal attributes")
+	// validate passive skills, level, talents and the rest
+	storedPal := pal
+	if err := s.validateStoredPal(&storedPal); err != nil {
+		return "", err
 	}
+	storedPal.ID = newPalID()
 
 	fmt.Println("Reading stored pals")
-	// Read existing stored pals data or create new slice if file doesn't exist
-	palStore, err := ReadStoredPals()
+	var storedCount int
+	err = s.updateStoredPals(profile, ActionAdd, source, func(palStore []models.PalSpecies) ([]models.PalSpecies, error) {
+		palStore = addToSpecies(palStore, palName, storedPal)
+		storedCount = len(palStore)
+		return palStore, nil
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	speciesStore := models.FindPalSpeciesFromStore(palStore, palName)
-	if speciesStore != nil {
-		fmt.Println("Pal species exists")
-	} else {
-		fmt.Println("New pal species")
-	}
+	fmt.Println("Passive skills data saved to stored_pals.json result is", storedCount)
 
-	fmt.Println("Validate passive skills")
-	// validate passive skills
-	registerPks := make([]models.PassiveSkill, 0)
-	for _, skill := range passiveSkill {
-		pks := models.FindPassiveSkill(passiveSkills, skill)
-		if pks == nil {
-			return fmt.Errorf("passive skill not found")
-		}
-		registerPks = append(registerPks, *pks)
-	}
+	return storedPal.ID, nil
 
-	skillNames := make([]string, len(registerPks))
-	for i, skill := range registerPks {
-		skillNames[i] = skill.Name
-	}
+}
 
+// addToSpecies appends storedPal to its species in the store, adding the species if needed
+func addToSpecies(palStore []models.PalSpecies, palName string, storedPal models.StoredPal) []models.PalSpecies {
+	speciesStore := models.FindPalSpeciesFromStore(palStore, palName)
 	if speciesStore == nil {
+		fmt.Println("New pal species")
 		palSpecies := &models.PalSpecies{
 			Name:       palName,
-			StoredPals: []models.StoredPal{{ID: 1, Gender: palGender, PassiveSkills: skillNames}},
+			StoredPals: []models.StoredPal{storedPal},
 		}
 		palStore = append(palStore, *palSpecies)
 	} else {
 		fmt.Println("Add new pal to species")
 
 		for i := range palStore {
-			if palStore[i].Name == palName {
+			if strings.EqualFold(palStore[i].Name, palName) {
 				fmt.Println("Add new pal to specie: ", palStore[i].Name)
 
 				// Update the existing species
-				palStore[i].StoredPals = append(palStore[i].StoredPals,
-					models.StoredPal{ID: len(palStore[i].StoredPals) + 1, Gender: palGender, PassiveSkills: skillNames})
+				palStore[i].StoredPals = append(palStore[i].StoredPals, storedPal)
 
 				break
 			}
 		}
 	}
 
-	// Convert the slice to JSON
-	jsonData, err := json.MarshalIndent(palStore, "", "  ")
+	return palStore
+}
+
+// validatePalName checks that palName is a species in the paldex and returns its paldex spelling
+func (s *Service) validatePalName(palName string) (string, error) {
+	pal, err := s.FindPal(palName)
+	if err != nil {
+		return "", err
+	}
+
+	if pal == nil {
+		return "", invalidPal("pal name not found")
+	}
+
+	return pal.Name, nil
+}
+
+// validateStoredPal checks the attributes of pal and normalizes the gender, passive
+// skill names and the container. Zero values mean the attribute is not tracked,
+// except for the gender, which is always required.
+func (s *Service) validateStoredPal(pal *models.StoredPal) error {
+	pal.Gender = strings.ToLower(strings.TrimSpace(pal.Gender))
+	if pal.Gender != "m" && pal.Gender != "f" {
+		return invalidPal("gender must be m or f")
+	}
+
+	skillNames, err := s.validatePassiveSkills(pal.PassiveSkills)
 	if err != nil {
 		return err
 	}
+	pal.PassiveSkills = skillNames
+
+	if pal.Level < 0 || pal.Level > s.maxPalLevel {
+		return invalidPal("level must be between 1 and %d, or 0 if unknown", s.maxPalLevel)
+	}
+	for _, talent := range []int{pal.Talents.HP, pal.Talents.Attack, pal.Talents.Defense} {
+		if talent < 0 || talent > maxTalent {
+			return invalidPal("talents must be between 0 and %d", maxTalent)
+		}
+	}
+	if pal.CondensationRank < 0 || pal.CondensationRank > maxCondensationRank {
+		return invalidPal("condensation rank must be between 0 and %d", maxCondensationRank)
+	}
+
+	activeSkills := make([]string, 0, len(pal.ActiveSkills))
+	for _, skill := range pal.ActiveSkills {
+		skill = strings.TrimSpace(skill)
+		if skill == "" {
+			return invalidPal("active skill name is empty")
+		}
+		activeSkills = append(activeSkills, skill)
+	}
+	pal.ActiveSkills = activeSkills
 
-	// Write the JSON data to a file
-	err = os.WriteFile("./data/stored_pals.json", jsonData, 0644)
+	container, err := normalizeContainer(pal.Container)
 	if err != nil {
 		return err
 	}
+	pal.Container = container
 
-	fmt.Println("Passive skills data saved to stored_pals.json result is", len(palStore))
+	pal.Nickname = strings.TrimSpace(pal.Nickname)
 
 	return nil
+}
+
+// normalizeContainer accepts "palbox", "party" or "base N" in any case
+func normalizeContainer(container string) (string, error) {
+	container = strings.ToLower(strings.Join(strings.Fields(container), " "))
+	switch container {
+	case "", ContainerPalbox, ContainerParty:
+		return container, nil
+	}
+
+	number, found := strings.CutPrefix(container, ContainerBase+" ")
+	if found {
+		if n, err := strconv.Atoi(number); err == nil && n > 0 {
+			return fmt.Sprintf("%s %d", ContainerBase, n), nil
+		}
+	}
+
+	return "", invalidPal("container must be palbox, party or base N")
+}
+
+// validatePassiveSkills checks every passive skill name and returns their canonical spelling.
+// A pal has at most maxPassiveSkills passives and none of them twice.
+func (s *Service) validatePassiveSkills(passiveSkill []string) ([]string, error) {
+	if len(passiveSkill) > maxPassiveSkills {
+		return nil, invalidPal("a pal can have at most %d passive skills", maxPassiveSkills)
+	}
+
+	skillNames := make([]string, 0, len(passiveSkill))
+	seen := make(map[string]bool)
+	for _, skill := range passiveSkill {
+		pks, err := s.FindPassiveSkill(skill)
+		if err != nil {
+			return nil, err
+		}
+		if pks == nil {
+			return nil, invalidPal("passive skill not found")
+		}
+		if seen[pks.Name] {
+			return nil, invalidPal("passive skill %s is listed twice", pks.Name)
+		}
+		seen[pks.Name] = true
+		skillNames = append(skillNames, pks.Name)
+	}
 
+	return skillNames, nil
 }
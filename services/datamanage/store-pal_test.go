@@ -0,0 +1,211 @@
+package datamanage
+
+import (
+	"errors"
+	"palworld_tools/dto"
+	"palworld_tools/models"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentAddAndRemove runs with -race to catch unsynchronized store access.
+// Every added pal of every second worker is removed again, so the final count is known.
+// The SQLite driver is too slow under the race detector, so only the JSON store is used.
+func TestConcurrentAddAndRemove(t *testing.T) {
+	const workers = 300
+
+	service := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			species := []string{"Lamball", "Cattiva", "Bushi"}[i%3]
+			id, err := service.AddPal(DefaultProfile, species, models.StoredPal{Gender: "m"}, SourceAPI)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if i%2 == 1 {
+				if err := service.RemovePal(DefaultProfile, id, SourceAPI); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent update failed: %v", err)
+	}
+
+	palStore, err := service.ReadStoredPals(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	pals := speciesPals(palStore)
+	if len(pals) != workers/2 {
+		t.Errorf("got %d stored pals, want %d", len(pals), workers/2)
+	}
+
+	seen := make(map[string]bool)
+	for _, pal := range pals {
+		if pal.Pal.ID == "" || seen[pal.Pal.ID] {
+			t.Fatalf("ID %q is empty or used twice", pal.Pal.ID)
+		}
+		seen[pal.Pal.ID] = true
+	}
+
+	// Every add and remove is in the journal
+	events, err := service.store.ReadStoreEvents(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoreEvents: %v", err)
+	}
+	if len(events) != workers+workers/2 {
+		t.Errorf("got %d journal events, want %d", len(events), workers+workers/2)
+	}
+}
+
+func TestAddPalStoresPaldexName(t *testing.T) {
+	service := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+
+	id, err := service.AddPal(DefaultProfile, "lAMBALL", models.StoredPal{Gender: "F"}, SourceAPI)
+	if err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	if err := service.UpdatePal(DefaultProfile, id, PalChanges{Name: ptr("bushi")}, SourceAPI); err != nil {
+		t.Fatalf("UpdatePal: %v", err)
+	}
+
+	palStore, err := service.ReadStoredPals(DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	if len(palStore) != 1 || palStore[0].Name != "Bushi" || palStore[0].StoredPals[0].Gender != "f" {
+		t.Errorf("got %+v, want a single female Bushi", palStore)
+	}
+}
+
+func TestGenderIsCheckedEverywhere(t *testing.T) {
+	service := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+	var validationErr *ValidationError
+
+	if _, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "x"}, SourceAPI); !errors.As(err, &validationErr) {
+		t.Errorf("AddPal: got %v, want a validation error", err)
+	}
+
+	id, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m"}, SourceAPI)
+	if err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	if err := service.UpdatePal(DefaultProfile, id, PalChanges{Gender: ptr("")}, SourceAPI); !errors.As(err, &validationErr) {
+		t.Errorf("UpdatePal: got %v, want a validation error", err)
+	}
+	if err := service.UpdatePal(DefaultProfile, "unknown", PalChanges{Gender: ptr("f")}, SourceAPI); !errors.Is(err, ErrStoredPalNotFound) {
+		t.Errorf("UpdatePal of an unknown ID: got %v, want ErrStoredPalNotFound", err)
+	}
+
+	records := []dto.StorePalRecord{{AddPalRequest: dto.AddPalRequest{Name: "Cattiva", Gender: "male"}}}
+	if _, err := service.ImportRecords(DefaultProfile, records, ImportModeMerge); !errors.As(err, new(*ImportError)) {
+		t.Errorf("ImportRecords: got %v, want an import error", err)
+	}
+
+	if got := countStoredPals(t, service, DefaultProfile); got != 1 {
+		t.Errorf("got %d stored pals, want 1", got)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestImportKeepsOnlyStoredIDs(t *testing.T) {
+	service := newTestService(t, testConfig(t.TempDir(), StoreBackendJSON))
+
+	storedID, err := service.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "m"}, SourceAPI)
+	if err != nil {
+		t.Fatalf("AddPal: %v", err)
+	}
+	unknownID := newPalID()
+
+	for _, mode := range []string{ImportModeMerge, ImportModeReplace} {
+		data := []byte(`[
+			{"id": "` + strings.ToUpper(storedID) + `", "name": "Bushi", "gender": "f"},
+			{"id": "1", "name": "Cattiva", "gender": "m"},
+			{"id": "` + unknownID + `", "name": "Cattiva", "gender": "f"},
+			{"name": "Lamball", "gender": "f"}
+		]`)
+		result, err := service.ImportStore(DefaultProfile, data, TransferFormatJSON, mode)
+		if err != nil {
+			t.Fatalf("%s: ImportStore: %v", mode, err)
+		}
+		if mode == ImportModeMerge && (result.Updated != 1 || result.Added != 3) {
+			t.Errorf("%s: got %+v, want 1 pal updated and 3 added", mode, result)
+		}
+
+		palStore, err := service.ReadStoredPals(DefaultProfile)
+		if err != nil {
+			t.Fatalf("ReadStoredPals: %v", err)
+		}
+		for _, pal := range speciesPals(palStore) {
+			if pal.Species == "Bushi" && pal.Pal.ID != storedID {
+				t.Errorf("%s: the stored pal got ID %q, want %q", mode, pal.Pal.ID, storedID)
+			}
+			if pal.Pal.ID == "1" || pal.Pal.ID == unknownID {
+				t.Errorf("%s: the client ID %q was kept", mode, pal.Pal.ID)
+			}
+		}
+	}
+
+	// Save files keep the instance ID of new pals, so importing them again updates the pals
+	records := []dto.StorePalRecord{{Id: unknownID, AddPalRequest: dto.AddPalRequest{Name: "Cattiva", Gender: "m"}}}
+	for _, want := range []dto.ImportResult{{Mode: ImportModeMerge, Added: 1}, {Mode: ImportModeMerge, Updated: 1}} {
+		result, err := service.ImportRecords(DefaultProfile, records, ImportModeMerge)
+		if err != nil {
+			t.Fatalf("ImportRecords: %v", err)
+		}
+		if result.Added != want.Added || result.Updated != want.Updated {
+			t.Errorf("ImportRecords: got %+v, want %+v", result, want)
+		}
+	}
+}
+
+func TestStoredPalLimits(t *testing.T) {
+	cfg := testConfig(t.TempDir(), StoreBackendJSON)
+	service := newTestService(t, cfg)
+
+	cases := []struct {
+		name  string
+		pal   models.StoredPal
+		valid bool
+	}{
+		{"level cap", models.StoredPal{Gender: "m", Level: DefaultMaxPalLevel}, true},
+		{"above the level cap", models.StoredPal{Gender: "m", Level: DefaultMaxPalLevel + 1}, false},
+		{"known passives", models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan", "Serious"}}, true},
+		{"five passives", models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan", "Serious", "Artisan", "Serious", "Artisan"}}, false},
+		{"duplicate passive", models.StoredPal{Gender: "m", PassiveSkills: []string{"Artisan", "artisan"}}, false},
+	}
+	for _, c := range cases {
+		_, err := service.AddPal(DefaultProfile, "Lamball", c.pal, SourceAPI)
+		if c.valid && err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+		if !c.valid && !errors.As(err, new(*ValidationError)) {
+			t.Errorf("%s: got %v, want a validation error", c.name, err)
+		}
+	}
+
+	// The level cap follows the configuration
+	cfg.MaxPalLevel = 80
+	raised, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if _, err := raised.AddPal(DefaultProfile, "Lamball", models.StoredPal{Gender: "f", Level: 80}, SourceAPI); err != nil {
+		t.Errorf("level 80 with MaxPalLevel 80: %v", err)
+	}
+}
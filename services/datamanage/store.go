@@ -0,0 +1,51 @@
+package datamanage
+
+import (
+	"fmt"
+	"palworld_tools/models"
+)
+
+const (
+	StoreBackendJSON   = "json"
+	StoreBackendSQLite = "sqlite"
+)
+
+// Store persists the paldex, passive skills and passive skill combos, which are shared,
+// and the pal store and its journal of changes for each profile
+type Store interface {
+	ReadPaldex() ([]models.Pal, error)
+	WritePaldex(pals []models.Pal) error
+	ReadPassiveSkills() ([]models.PassiveSkill, error)
+	WritePassiveSkills(passiveSkills []models.PassiveSkill) error
+	ReadPassiveSkillCombos() ([]models.PassiveSkillCombo, error)
+	WritePassiveSkillCombos(combos []models.PassiveSkillCombo) error
+	ReadStoredPals(profile string) ([]models.PalSpecies, error)
+	WriteStoredPals(profile string, palStore []models.PalSpecies) error
+	ReadStoreEvents(profile string) ([]models.StoreEvent, error)
+	AppendStoreEvent(profile string, event models.StoreEvent) error
+	// LastStoreEvent returns the latest journal event, or nil if the journal is empty
+	LastStoreEvent(profile string) (*models.StoreEvent, error)
+	// RemoveLastStoreEvent drops the latest journal event if it has the given ID,
+	// for a change that never reached the pal store
+	RemoveLastStoreEvent(profile string, id int) error
+	ListProfiles() ([]string, error)
+	CreateProfile(name string) error
+	RenameProfile(name string, newName string) error
+	DeleteProfile(name string) error
+	// DataVersion changes whenever the paldex, passive skills or passive skill combos
+	// are changed from outside this process
+	DataVersion() (string, error)
+}
+
+// OpenStore opens the store for the given backend name. The JSON files in files are
+// used by the JSON backend, and imported by the SQLite backend when it creates sqliteFile.
+func OpenStore(backend string, files DataFiles, sqliteFile string) (Store, error) {
+	switch backend {
+	case "", StoreBackendJSON:
+		return NewJSONStore(files), nil
+	case StoreBackendSQLite:
+		return NewSQLiteStore(sqliteFile, NewJSONStore(files))
+	default:
+		return nil, fmt.Errorf("unknown store backend: %s", backend)
+	}
+}
@@ -0,0 +1,93 @@
+package datamanage
+
+import (
+	"fmt"
+	"palworld_tools/models"
+	"strings"
+)
+
+// PalChanges lists the fields to change on a stored pal. Nil fields are left as they are.
+type PalChanges struct {
+	Name             *string
+	Gender           *string
+	Nickname         *string
+	Level            *int
+	Talents          *models.Talents
+	CondensationRank *int
+	Container        *string
+	PassiveSkills    []string
+	ActiveSkills     []string
+}
+
+// UpdatePal changes the species or attributes of a stored pal, with the same
+// checks as AddPal. The pal keeps its ID, also when it moves to another species.
+func (s *Service) UpdatePal(profile string, id string, changes PalChanges, source string) error {
+
+	if changes.Name != nil {
+		fmt.Println("Validate pal name")
+		palName, err := s.validatePalName(*changes.Name)
+		if err != nil {
+			return err
+		}
+		changes.Name = &palName
+	}
+
+	return s.updateStoredPals(profile, ActionUpdate, source, func(palStore []models.PalSpecies) ([]models.PalSpecies, error) {
+		i, j, ok := findStoredPal(palStore, id)
+		if !ok {
+			return nil, ErrStoredPalNotFound
+		}
+
+		storedPal := &palStore[i].StoredPals[j]
+		updated := *storedPal
+		changes.apply(&updated)
+
+		fmt.Println("Validate pal attributes")
+		if err := s.validateStoredPal(&updated); err != nil {
+			return nil, err
+		}
+		*storedPal = updated
+
+		if changes.Name == nil || strings.EqualFold(palStore[i].Name, *changes.Name) {
+			return palStore, nil
+		}
+
+		// move the pal to its new species
+		fmt.Println("Move pal to species: ", *changes.Name)
+		moved := *storedPal
+		palStore[i].StoredPals = append(palStore[i].StoredPals[:j], palStore[i].StoredPals[j+1:]...)
+		if len(palStore[i].StoredPals) == 0 {
+			palStore = append(palStore[:i], palStore[i+1:]...)
+		}
+
+		return addToSpecies(palStore, *changes.Name, moved), nil
+	})
+
+}
+
+func (c PalChanges) apply(pal *models.StoredPal) {
+	if c.Gender != nil {
+		pal.Gender = *c.Gender
+	}
+	if c.Nickname != nil {
+		pal.Nickname = *c.Nickname
+	}
+	if c.Level != nil {
+		pal.Level = *c.Level
+	}
+	if c.Talents != nil {
+		pal.Talents = *c.Talents
+	}
+	if c.CondensationRank != nil {
+		pal.CondensationRank = *c.CondensationRank
+	}
+	if c.Container != nil {
+		pal.Container = *c.Container
+	}
+	if c.PassiveSkills != nil {
+		pal.PassiveSkills = c.PassiveSkills
+	}
+	if c.ActiveSkills != nil {
+		pal.ActiveSkills = c.ActiveSkills
+	}
+}
@@ -0,0 +1,38 @@
+package datamanage
+
+import (
+	"palworld_tools/models"
+)
+
+// WritePaldex replaces the paldex and reloads the data cache
+func (s *Service) WritePaldex(pals []models.Pal) error {
+	if err := s.store.WritePaldex(pals); err != nil {
+		return err
+	}
+	return s.ReloadData()
+}
+
+func (s *Service) WritePassiveSkills(passiveSkills []models.PassiveSkill) error {
+	if err := s.store.WritePassiveSkills(passiveSkills); err != nil {
+		return err
+	}
+	return s.ReloadData()
+}
+
+func (s *Service) WritePassiveSkillCombos(combos []models.PassiveSkillCombo) error {
+	if err := s.store.WritePassiveSkillCombos(combos); err != nil {
+		return err
+	}
+	return s.ReloadData()
+}
+
+// updateStoredPals reads the pal store of a profile, applies update and writes the result back
+// while holding the store lock. Nothing is written if update returns an error.
+// The changed pals are recorded in the journal under action and source.
+func (s *Service) updateStoredPals(profile string, action string, source string, update func(palStore []models.PalSpecies) ([]models.PalSpecies, error)) error {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	_, err := s.commitStoredPals(profile, action, source, 0, update)
+	return err
+}
@@ -2,8 +2,8 @@ package options
 
 import "palworld_tools/services/datamanage"
 
-func GetPalSpecies() []string {
-	palSpecies, err := datamanage.ReadPaldex()
+func GetPalSpecies(service *datamanage.Service) []string {
+	palSpecies, err := service.Paldex()
 	if err != nil {
 		return nil
 	}
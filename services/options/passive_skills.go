@@ -4,9 +4,9 @@ import (
 	"palworld_tools/services/datamanage"
 )
 
-func GetPassiveSkills() []string {
+func GetPassiveSkills(service *datamanage.Service) []string {
 
-	passiveSkills, err := datamanage.ReadPassiveSkills()
+	passiveSkills, err := service.PassiveSkills()
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,88 @@
+package savefile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	saveTypeUncompressed = 0x30
+	saveTypeZlib         = 0x31
+	saveTypeZlibTwice    = 0x32
+)
+
+// ErrOodleSave is returned for saves compressed with Oodle (the "PlM" magic). There is
+// no Oodle decoder in Go, so only zlib compressed ("PlZ") saves can be read.
+var ErrOodleSave = errors.New("save uses Oodle compression (PlM), which current game versions write and which is not supported: only zlib compressed (PlZ) saves from older game versions can be imported")
+
+// decompressSave unpacks a Palworld .sav file into its GVAS data. The file starts
+// with the uncompressed and compressed lengths, the "PlZ" magic and the save type,
+// optionally behind a "CNK" chunk header.
+func decompressSave(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("save file is too short")
+	}
+
+	offset := 0
+	if string(data[8:11]) == "CNK" {
+		if len(data) < 24 {
+			return nil, fmt.Errorf("save file is too short")
+		}
+		offset = 12
+	}
+
+	uncompressedLen := binary.LittleEndian.Uint32(data[offset : offset+4])
+	compressedLen := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+	magic := string(data[offset+8 : offset+11])
+	saveType := data[offset+11]
+	body := data[offset+12:]
+
+	if magic == "PlM" {
+		return nil, ErrOodleSave
+	}
+	if magic != "PlZ" {
+		return nil, fmt.Errorf("not a Palworld save file")
+	}
+
+	var gvas []byte
+	var err error
+	switch saveType {
+	case saveTypeUncompressed:
+		gvas = body
+	case saveTypeZlib:
+		gvas, err = inflate(body)
+	case saveTypeZlibTwice:
+		gvas, err = inflate(body)
+		if err == nil {
+			if uint32(len(gvas)) != compressedLen {
+				return nil, fmt.Errorf("save file compressed length mismatch")
+			}
+			gvas, err = inflate(gvas)
+		}
+	default:
+		return nil, fmt.Errorf("unknown save type 0x%x", saveType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompressing save file: %v", err)
+	}
+
+	if uint32(len(gvas)) != uncompressedLen {
+		return nil, fmt.Errorf("save file uncompressed length mismatch")
+	}
+
+	return gvas, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
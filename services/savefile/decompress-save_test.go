@@ -0,0 +1,59 @@
+package savefile
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecompressSaveFixtures(t *testing.T) {
+	want := levelGvas()
+	for _, name := range []string{"Level.sav", "Level-zlib.sav", "Level-cnk.sav"} {
+		gvas, err := decompressSave(readFixture(t, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(gvas, want) {
+			t.Errorf("%s: decompressed data differs from the fixture GVAS", name)
+		}
+	}
+}
+
+func TestDecompressSaveUncompressed(t *testing.T) {
+	want := levelGvas()
+	gvas, err := decompressSave(wrapSave(want, "PlZ", saveTypeUncompressed, false))
+	if err != nil {
+		t.Fatalf("decompressSave: %v", err)
+	}
+	if !bytes.Equal(gvas, want) {
+		t.Errorf("uncompressed data differs from the fixture GVAS")
+	}
+}
+
+func TestDecompressSaveRejectsOodle(t *testing.T) {
+	_, err := decompressSave(readFixture(t, "Level-plm.sav"))
+	if !errors.Is(err, ErrOodleSave) {
+		t.Errorf("got %v, want ErrOodleSave", err)
+	}
+}
+
+func TestDecompressSaveErrors(t *testing.T) {
+	gvas := levelGvas()
+	mismatched := wrapSave(gvas, "PlZ", saveTypeZlib, false)
+	mismatched[0]++
+	notZlib := wrapSave(gvas, "PlZ", saveTypeUncompressed, false)
+	notZlib[11] = saveTypeZlib
+
+	for name, data := range map[string][]byte{
+		"too short":       []byte("PlZ"),
+		"bad magic":       wrapSave(gvas, "XYZ", saveTypeZlib, false),
+		"unknown type":    wrapSave(gvas, "PlZ", 0x39, false),
+		"length mismatch": mismatched,
+		"not zlib":        notZlib,
+	} {
+		if _, err := decompressSave(data); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
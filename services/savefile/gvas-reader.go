@@ -0,0 +1,366 @@
+package savefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of save data")
+
+// gvasReader reads the little endian values of an Unreal Engine GVAS save
+type gvasReader struct {
+	data []byte
+	pos  int
+}
+
+// property is one tagged property. Its value is kept as raw bytes and only decoded
+// when asked for, so the parts of the save we don't need are skipped by size.
+type property struct {
+	typ       string
+	innerType string // struct name, array element type, enum name or map key type
+	valueType string // map value type
+	boolValue bool
+	value     []byte
+}
+
+// properties are the properties of a struct by name
+type properties map[string]*property
+
+func (r *gvasReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *gvasReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.remaining() < n {
+		return nil, errUnexpectedEnd
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *gvasReader) u8() (byte, error) {
+	b, err := r.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *gvasReader) u16() (uint16, error) {
+	b, err := r.bytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b), nil
+}
+
+func (r *gvasReader) u32() (uint32, error) {
+	b, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (r *gvasReader) i32() (int32, error) {
+	v, err := r.u32()
+	return int32(v), err
+}
+
+func (r *gvasReader) u64() (uint64, error) {
+	b, err := r.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// fstring reads an Unreal string: a length, then ASCII or (for a negative length) UTF-16
+// characters, including a trailing null
+func (r *gvasReader) fstring() (string, error) {
+	size, err := r.i32()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case size == 0:
+		return "", nil
+	case size > 0:
+		b, err := r.bytes(int(size))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\x00"), nil
+	default:
+		if size == math.MinInt32 {
+			return "", errUnexpectedEnd
+		}
+		b, err := r.bytes(int(-size) * 2)
+		if err != nil {
+			return "", err
+		}
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			units = append(units, binary.LittleEndian.Uint16(b[i:]))
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00"), nil
+	}
+}
+
+// optionalGuid skips the property GUID that follows a flag byte
+func (r *gvasReader) optionalGuid() error {
+	flag, err := r.u8()
+	if err != nil {
+		return err
+	}
+	if flag != 0 {
+		_, err = r.bytes(16)
+	}
+	return err
+}
+
+// skipHeader reads the GVAS header up to the first property
+func (r *gvasReader) skipHeader() error {
+	magic, err := r.bytes(4)
+	if err != nil {
+		return err
+	}
+	if string(magic) != "GVAS" {
+		return fmt.Errorf("save data is not GVAS")
+	}
+
+	saveGameVersion, err := r.i32()
+	if err != nil {
+		return err
+	}
+	// package versions, UE5 only from save game version 3
+	versions := 4
+	if saveGameVersion >= 3 {
+		versions += 4
+	}
+	// engine major, minor and patch version and changelist
+	if _, err := r.bytes(versions + 2 + 2 + 2 + 4); err != nil {
+		return err
+	}
+	if _, err := r.fstring(); err != nil { // engine branch
+		return err
+	}
+	if _, err := r.i32(); err != nil { // custom version format
+		return err
+	}
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	// custom versions, a GUID and a version each
+	if _, err := r.bytes(int(count) * 20); err != nil {
+		return err
+	}
+	_, err = r.fstring() // save game class name
+	return err
+}
+
+// readProperties reads tagged properties until the "None" terminator
+func (r *gvasReader) readProperties() (properties, error) {
+	props := make(properties)
+	for {
+		name, err := r.fstring()
+		if err != nil {
+			return nil, err
+		}
+		if name == "None" {
+			return props, nil
+		}
+
+		prop, err := r.readProperty()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		props[name] = prop
+	}
+}
+
+// readProperty reads the type and type specific header of a property tag,
+// then keeps its value bytes
+func (r *gvasReader) readProperty() (*property, error) {
+	typ, err := r.fstring()
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.u64()
+	if err != nil {
+		return nil, err
+	}
+	size &= math.MaxUint32 // the high half is the array index
+
+	prop := &property{typ: typ}
+	switch typ {
+	case "StructProperty":
+		if prop.innerType, err = r.fstring(); err != nil {
+			return nil, err
+		}
+		if _, err = r.bytes(16); err != nil { // struct GUID
+			return nil, err
+		}
+	case "ArrayProperty", "SetProperty", "ByteProperty", "EnumProperty":
+		if prop.innerType, err = r.fstring(); err != nil {
+			return nil, err
+		}
+	case "MapProperty":
+		if prop.innerType, err = r.fstring(); err != nil {
+			return nil, err
+		}
+		if prop.valueType, err = r.fstring(); err != nil {
+			return nil, err
+		}
+	case "BoolProperty":
+		value, err := r.u8()
+		if err != nil {
+			return nil, err
+		}
+		prop.boolValue = value != 0
+	}
+	if err := r.optionalGuid(); err != nil {
+		return nil, err
+	}
+
+	if prop.value, err = r.bytes(int(size)); err != nil {
+		return nil, err
+	}
+	return prop, nil
+}
+
+func (p *property) reader() *gvasReader {
+	return &gvasReader{data: p.value}
+}
+
+// structProperties decodes a struct made of tagged properties
+func (p *property) structProperties() (properties, error) {
+	if p.typ != "StructProperty" {
+		return nil, fmt.Errorf("expected StructProperty, got %s", p.typ)
+	}
+	return p.reader().readProperties()
+}
+
+// int decodes the integer types, including a ByteProperty without enum
+func (p *property) int() (int, error) {
+	r := p.reader()
+	switch p.typ {
+	case "IntProperty":
+		v, err := r.i32()
+		return int(v), err
+	case "UInt32Property":
+		v, err := r.u32()
+		return int(v), err
+	case "Int64Property", "UInt64Property":
+		v, err := r.u64()
+		return int(v), err
+	case "UInt16Property", "Int16Property":
+		v, err := r.u16()
+		return int(v), err
+	case "ByteProperty", "Int8Property":
+		if p.innerType != "" && p.innerType != "None" {
+			return 0, fmt.Errorf("expected byte value, got enum %s", p.innerType)
+		}
+		v, err := r.u8()
+		return int(v), err
+	default:
+		return 0, fmt.Errorf("expected integer, got %s", p.typ)
+	}
+}
+
+// string decodes StrProperty, NameProperty, EnumProperty and an enum ByteProperty
+func (p *property) string() (string, error) {
+	switch p.typ {
+	case "StrProperty", "NameProperty", "EnumProperty", "ByteProperty":
+		return p.reader().fstring()
+	default:
+		return "", fmt.Errorf("expected string, got %s", p.typ)
+	}
+}
+
+// guid decodes a Guid struct in the same text form as the save tools use
+func (p *property) guid() (string, error) {
+	if p.typ != "StructProperty" || p.innerType != "Guid" || len(p.value) != 16 {
+		return "", fmt.Errorf("expected Guid struct")
+	}
+	b := p.value
+	return fmt.Sprintf("%02x%02x%02x%02x-%02x%02x-%02x%02x-%02x%02x-%02x%02x%02x%02x%02x%02x",
+		b[3], b[2], b[1], b[0], b[7], b[6], b[5], b[4],
+		b[11], b[10], b[9], b[8], b[15], b[14], b[13], b[12]), nil
+}
+
+// byteArray decodes an array of bytes, such as the RawData blobs
+func (p *property) byteArray() ([]byte, error) {
+	if p.typ != "ArrayProperty" || p.innerType != "ByteProperty" {
+		return nil, fmt.Errorf("expected byte array")
+	}
+	r := p.reader()
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	return r.bytes(int(count))
+}
+
+// stringArray decodes an array of names, strings or enum values
+func (p *property) stringArray() ([]string, error) {
+	if p.typ != "ArrayProperty" {
+		return nil, fmt.Errorf("expected ArrayProperty, got %s", p.typ)
+	}
+	switch p.innerType {
+	case "NameProperty", "StrProperty", "EnumProperty":
+	default:
+		return nil, fmt.Errorf("expected string array, got %s array", p.innerType)
+	}
+
+	r := p.reader()
+	count, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		value, err := r.fstring()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// structMap decodes a map whose keys and values are both structs made of tagged properties
+func (p *property) structMap(each func(key properties, value properties) error) error {
+	if p.typ != "MapProperty" || p.innerType != "StructProperty" || p.valueType != "StructProperty" {
+		return fmt.Errorf("expected map of structs")
+	}
+
+	r := p.reader()
+	if _, err := r.u32(); err != nil { // keys to remove
+		return err
+	}
+	count, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		key, err := r.readProperties()
+		if err != nil {
+			return err
+		}
+		value, err := r.readProperties()
+		if err != nil {
+			return err
+		}
+		if err := each(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package savefile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+var update = flag.Bool("update", false, "rewrite the save fixtures in testdata")
+
+const (
+	fixturePlayerGuid = "2a5d0f7e-0000-0000-0000-000000000001"
+	fixtureLamballID  = "6b1c3e90-4a2f-4c1d-9e8b-7f6a5d4c3b2a"
+	fixtureFoxparksID = "0d9e8f7a-1b2c-4d3e-8f9a-0b1c2d3e4f5a"
+)
+
+// gvasWriter builds GVAS data in the layout gvasReader reads, for the fixtures
+type gvasWriter struct {
+	bytes.Buffer
+}
+
+func (w *gvasWriter) u8(v byte)    { w.WriteByte(v) }
+func (w *gvasWriter) u16(v uint16) { w.Write(binary.LittleEndian.AppendUint16(nil, v)) }
+func (w *gvasWriter) u32(v uint32) { w.Write(binary.LittleEndian.AppendUint32(nil, v)) }
+func (w *gvasWriter) u64(v uint64) { w.Write(binary.LittleEndian.AppendUint64(nil, v)) }
+
+// fstring writes ASCII text as is and anything else as UTF-16 with a negative length
+func (w *gvasWriter) fstring(s string) {
+	for _, r := range s {
+		if r > 127 {
+			units := append(utf16.Encode([]rune(s)), 0)
+			w.u32(uint32(-int32(len(units))))
+			for _, unit := range units {
+				w.u16(unit)
+			}
+			return
+		}
+	}
+	w.u32(uint32(len(s) + 1))
+	w.WriteString(s)
+	w.u8(0)
+}
+
+// tag writes a property name, type and value size, then the type specific header
+// written by header and the value
+func tag(name string, typ string, header func(w *gvasWriter), value []byte) []byte {
+	w := &gvasWriter{}
+	w.fstring(name)
+	w.fstring(typ)
+	w.u64(uint64(len(value)))
+	if header != nil {
+		header(w)
+	}
+	w.u8(0) // no property GUID
+	w.Write(value)
+	return w.Bytes()
+}
+
+// props joins properties and ends them with "None"
+func props(list ...[]byte) []byte {
+	w := &gvasWriter{}
+	for _, prop := range list {
+		w.Write(prop)
+	}
+	w.fstring("None")
+	return w.Bytes()
+}
+
+func structProp(name string, structType string, value []byte) []byte {
+	return tag(name, "StructProperty", func(w *gvasWriter) {
+		w.fstring(structType)
+		w.Write(make([]byte, 16))
+	}, value)
+}
+
+// guidProp writes a Guid struct from the text form guid() returns
+func guidProp(name string, text string) []byte {
+	raw, err := hex.DecodeString(strings.ReplaceAll(text, "-", ""))
+	if err != nil || len(raw) != 16 {
+		panic("bad guid " + text)
+	}
+	value := make([]byte, 16)
+	for i := 0; i < 16; i += 4 {
+		value[i], value[i+1], value[i+2], value[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return structProp(name, "Guid", value)
+}
+
+func intProp(name string, v int32) []byte {
+	return tag(name, "IntProperty", nil, binary.LittleEndian.AppendUint32(nil, uint32(v)))
+}
+
+func byteProp(name string, v byte) []byte {
+	return tag(name, "ByteProperty", func(w *gvasWriter) { w.fstring("None") }, []byte{v})
+}
+
+func boolProp(name string, v bool) []byte {
+	w := &gvasWriter{}
+	w.fstring(name)
+	w.fstring("BoolProperty")
+	w.u64(0)
+	if v {
+		w.u8(1)
+	} else {
+		w.u8(0)
+	}
+	w.u8(0)
+	return w.Bytes()
+}
+
+func stringValue(s string) []byte {
+	w := &gvasWriter{}
+	w.fstring(s)
+	return w.Bytes()
+}
+
+func strProp(name string, s string) []byte {
+	return tag(name, "StrProperty", nil, stringValue(s))
+}
+
+func nameProp(name string, s string) []byte {
+	return tag(name, "NameProperty", nil, stringValue(s))
+}
+
+func enumProp(name string, enumType string, s string) []byte {
+	return tag(name, "EnumProperty", func(w *gvasWriter) { w.fstring(enumType) }, stringValue(s))
+}
+
+func nameArrayProp(name string, values ...string) []byte {
+	w := &gvasWriter{}
+	w.u32(uint32(len(values)))
+	for _, value := range values {
+		w.fstring(value)
+	}
+	return tag(name, "ArrayProperty", func(w *gvasWriter) { w.fstring("NameProperty") }, w.Bytes())
+}
+
+func byteArrayProp(name string, data []byte) []byte {
+	w := &gvasWriter{}
+	w.u32(uint32(len(data)))
+	w.Write(data)
+	return tag(name, "ArrayProperty", func(w *gvasWriter) { w.fstring("ByteProperty") }, w.Bytes())
+}
+
+// structMapProp writes a map of structs from alternating key and value properties
+func structMapProp(name string, entries ...[]byte) []byte {
+	w := &gvasWriter{}
+	w.u32(0) // keys to remove
+	w.u32(uint32(len(entries) / 2))
+	for _, entry := range entries {
+		w.Write(entry)
+	}
+	return tag(name, "MapProperty", func(w *gvasWriter) {
+		w.fstring("StructProperty")
+		w.fstring("StructProperty")
+	}, w.Bytes())
+}
+
+// character writes one CharacterSaveParameterMap entry. Like the game, RawData
+// ends with a few bytes after the properties.
+func character(playerGuid string, instanceID string, saveParameter ...[]byte) [][]byte {
+	key := props(
+		guidProp("PlayerUId", playerGuid),
+		guidProp("InstanceId", instanceID),
+		strProp("DebugName", ""),
+	)
+	raw := props(structProp("SaveParameter", "PalIndividualCharacterSaveParameter", props(saveParameter...)))
+	raw = append(raw, make([]byte, 4+16)...)
+	return [][]byte{key, props(byteArrayProp("RawData", raw))}
+}
+
+// levelGvas is the GVAS data of the fixtures: a player, two pals they own and a wild pal
+func levelGvas() []byte {
+	return levelGvasWith(fixtureCharacters()...)
+}
+
+func fixtureCharacters() [][]byte {
+	entries := make([][]byte, 0)
+	entries = append(entries, character(fixturePlayerGuid, "9c8b7a6f-5e4d-3c2b-1a09-f8e7d6c5b4a3",
+		boolProp("IsPlayer", true),
+		strProp("NickName", "Player One"),
+		intProp("Level", 20),
+	)...)
+	entries = append(entries, character(zeroGuid, fixtureLamballID,
+		nameProp("CharacterID", "SheepBall"),
+		enumProp("Gender", "EPalGenderType", "EPalGenderType::Female"),
+		byteProp("Level", 12),
+		byteProp("Rank", 3),
+		byteProp("Talent_HP", 50),
+		byteProp("Talent_Shot", 60),
+		byteProp("Talent_Defense", 70),
+		nameArrayProp("PassiveSkillList", "CraftSpeed_up2", "Deffence_up1"),
+		strProp("NickName", "Woolly"),
+		guidProp("OwnerPlayerUId", fixturePlayerGuid),
+	)...)
+	entries = append(entries, character(zeroGuid, fixtureFoxparksID,
+		nameProp("CharacterID", "BOSS_Kitsunebi"),
+		enumProp("Gender", "EPalGenderType", "EPalGenderType::Male"),
+		intProp("Level", 30),
+		nameArrayProp("PassiveSkillList", "Rare", "Unknown_Passive"),
+		strProp("NickName", "Füchslein"),
+		guidProp("OwnerPlayerUId", fixturePlayerGuid),
+	)...)
+	entries = append(entries, character(zeroGuid, "11111111-2222-3333-4444-555555555555",
+		nameProp("CharacterID", "Penguin"),
+		enumProp("Gender", "EPalGenderType", "EPalGenderType::Male"),
+		byteProp("Level", 5),
+		guidProp("OwnerPlayerUId", zeroGuid),
+	)...)
+	return entries
+}
+
+// levelGvasWith is GVAS data with the given CharacterSaveParameterMap entries
+func levelGvasWith(entries ...[]byte) []byte {
+	dateTime := make([]byte, 8)
+	world := props(
+		structMapProp("CharacterSaveParameterMap", entries...),
+		intProp("SaveVersion", 1),
+	)
+	root := props(
+		intProp("Version", 100),
+		structProp("Timestamp", "DateTime", dateTime),
+		structProp("worldSaveData", "PalWorldSaveData", world),
+	)
+
+	w := &gvasWriter{}
+	w.WriteString("GVAS")
+	w.u32(3)   // save game version
+	w.u32(522) // UE4 package version
+	w.u32(1009)
+	w.u16(5) // engine 5.1.1
+	w.u16(1)
+	w.u16(1)
+	w.u32(0)
+	w.fstring("++UE5+Release-5.1")
+	w.u32(3) // custom version format
+	w.u32(1)
+	w.Write(make([]byte, 20))
+	w.fstring("/Script/Pal.PalWorldSaveGame")
+	w.Write(root)
+	return w.Bytes()
+}
+
+func deflate(data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := zlib.NewWriter(&buffer)
+	writer.Write(data)
+	writer.Close()
+	return buffer.Bytes()
+}
+
+// wrapSave packs GVAS data into a .sav file with the given magic and save type,
+// behind a "CNK" chunk header when chunked is set
+func wrapSave(gvas []byte, magic string, saveType byte, chunked bool) []byte {
+	body := gvas
+	compressedLen := len(gvas)
+	switch saveType {
+	case saveTypeZlib:
+		body = deflate(gvas)
+		compressedLen = len(body)
+	case saveTypeZlibTwice:
+		once := deflate(gvas)
+		body = deflate(once)
+		compressedLen = len(once)
+	}
+
+	w := &gvasWriter{}
+	if chunked {
+		w.u32(uint32(len(gvas)))
+		w.u32(uint32(compressedLen))
+		w.WriteString("CNK")
+		w.u8(saveType)
+	}
+	w.u32(uint32(len(gvas)))
+	w.u32(uint32(compressedLen))
+	w.WriteString(magic)
+	w.u8(saveType)
+	w.Write(body)
+	return w.Bytes()
+}
+
+// saveFixtures are the files under testdata. Level-plm.sav only has the header
+// of an Oodle compressed save, as there is no Oodle decoder to test against.
+func saveFixtures() map[string][]byte {
+	gvas := levelGvas()
+	return map[string][]byte{
+		"Level.sav":      wrapSave(gvas, "PlZ", saveTypeZlibTwice, false),
+		"Level-zlib.sav": wrapSave(gvas, "PlZ", saveTypeZlib, false),
+		"Level-cnk.sav":  wrapSave(gvas, "PlZ", saveTypeZlibTwice, true),
+		"Level-plm.sav":  wrapSave([]byte("oodle compressed data"), "PlM", saveTypeZlib, false),
+	}
+}
+
+// TestWriteFixtures rewrites testdata with go test ./services/savefile -run TestWriteFixtures -update
+func TestWriteFixtures(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to rewrite the fixtures")
+	}
+	for name, data := range saveFixtures() {
+		if err := os.WriteFile(filepath.Join("testdata", name), data, 0644); err != nil {
+			t.Fatalf("WriteFile %s: %v", name, err)
+		}
+	}
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,276 @@
+package savefile
+
+import (
+	"errors"
+	"fmt"
+	"palworld_tools/dto"
+	"palworld_tools/services/datamanage"
+	"sort"
+	"strings"
+)
+
+const zeroGuid = "00000000-0000-0000-0000-000000000000"
+
+// savePal is an owned pal as it is stored in Level.sav
+type savePal struct {
+	instanceID       string
+	characterID      string
+	gender           string
+	nickname         string
+	level            int
+	talentHP         int
+	talentAttack     int
+	talentDefense    int
+	condensationRank int
+	passives         []string
+}
+
+// ImportLevelSave reads every owned pal from a Palworld Level.sav file and loads them
+// into the store of profile. Pals keep their instance ID from the save, so importing a newer
+// save again in merge mode updates them. Pals with a species code that can't be
+// mapped to the paldex or that fail validation are skipped and reported with the reason,
+// passives that can't be mapped are left out.
+func ImportLevelSave(service *datamanage.Service, profile string, data []byte, mode string) (*dto.SaveImportResult, error) {
+	savePals, err := readLevelSave(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pals, err := service.Paldex()
+	if err != nil {
+		return nil, err
+	}
+	passiveSkills, err := service.PassiveSkills()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.SaveImportResult{
+		Found:           len(savePals),
+		Skipped:         make([]dto.SkippedSavePal, 0),
+		UnknownPassives: make([]string, 0),
+	}
+	unknownPassives := make(map[string]bool)
+	records := make([]dto.StorePalRecord, 0, len(savePals))
+	recordPals := make([]savePal, 0, len(savePals))
+	for _, pal := range savePals {
+		species, ok := speciesName(pals, pal.characterID)
+		if !ok {
+			result.Skipped = append(result.Skipped, dto.SkippedSavePal{
+				Id: pal.instanceID, CharacterId: pal.characterID, Reason: "unknown species code",
+			})
+			continue
+		}
+		if pal.gender == "" {
+			result.Skipped = append(result.Skipped, dto.SkippedSavePal{
+				Id: pal.instanceID, CharacterId: pal.characterID, Reason: "no gender",
+			})
+			continue
+		}
+
+		passives := make([]string, 0, len(pal.passives))
+		for _, code := range pal.passives {
+			name, ok := passiveName(passiveSkills, code)
+			if !ok {
+				unknownPassives[code] = true
+				continue
+			}
+			passives = append(passives, name)
+		}
+
+		records = append(records, dto.StorePalRecord{
+			Id: pal.instanceID,
+			AddPalRequest: dto.AddPalRequest{
+				Name:     species,
+				Gender:   pal.gender,
+				Nickname: pal.nickname,
+				Level:    pal.level,
+				Talents: dto.Talents{
+					HP:      pal.talentHP,
+					Attack:  pal.talentAttack,
+					Defense: pal.talentDefense,
+				},
+				CondensationRank: pal.condensationRank,
+				PassiveSkills:    passives,
+			},
+		})
+		recordPals = append(recordPals, pal)
+	}
+
+	for code := range unknownPassives {
+		result.UnknownPassives = append(result.UnknownPassives, code)
+	}
+	sort.Strings(result.UnknownPassives)
+
+	imported, err := service.ImportRecords(profile, records, mode)
+	var importErr *datamanage.ImportError
+	if errors.As(err, &importErr) {
+		// Pals the store won't accept are skipped with the reason, the rest is imported
+		failed := make(map[int]bool)
+		for _, row := range importErr.Rows {
+			pal := recordPals[row.Row-1]
+			failed[row.Row-1] = true
+			result.Skipped = append(result.Skipped, dto.SkippedSavePal{
+				Id: pal.instanceID, CharacterId: pal.characterID, Reason: row.Error,
+			})
+		}
+		valid := make([]dto.StorePalRecord, 0, len(records))
+		for i, record := range records {
+			if !failed[i] {
+				valid = append(valid, record)
+			}
+		}
+		imported, err = service.ImportRecords(profile, valid, mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.ImportResult = *imported
+
+	fmt.Println("Save import:", result.Found, "pals found,", len(result.Skipped), "skipped")
+
+	return result, nil
+}
+
+// readLevelSave decompresses Level.sav and pulls every pal owned by a player
+// out of worldSaveData.CharacterSaveParameterMap
+func readLevelSave(data []byte) ([]savePal, error) {
+	gvas, err := decompressSave(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &gvasReader{data: gvas}
+	if err := reader.skipHeader(); err != nil {
+		return nil, err
+	}
+	root, err := reader.readProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	worldProp, ok := root["worldSaveData"]
+	if !ok {
+		return nil, fmt.Errorf("save has no worldSaveData, is this Level.sav?")
+	}
+	world, err := worldProp.structProperties()
+	if err != nil {
+		return nil, fmt.Errorf("worldSaveData: %v", err)
+	}
+	characters, ok := world["CharacterSaveParameterMap"]
+	if !ok {
+		return nil, fmt.Errorf("save has no CharacterSaveParameterMap")
+	}
+
+	pals := make([]savePal, 0)
+	err = characters.structMap(func(key properties, value properties) error {
+		instanceID := ""
+		if prop, ok := key["InstanceId"]; ok {
+			instanceID, _ = prop.guid()
+		}
+
+		rawData, ok := value["RawData"]
+		if !ok {
+			return nil
+		}
+		raw, err := rawData.byteArray()
+		if err != nil {
+			return err
+		}
+
+		pal, owned, err := readCharacter(raw)
+		if err != nil {
+			return fmt.Errorf("character %s: %v", instanceID, err)
+		}
+		if !owned {
+			return nil
+		}
+		pal.instanceID = instanceID
+		pals = append(pals, pal)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("CharacterSaveParameterMap: %v", err)
+	}
+
+	return pals, nil
+}
+
+// readCharacter decodes the RawData of one character. Players and pals without an
+// owner, such as wild pals, are reported as not owned.
+func readCharacter(raw []byte) (savePal, bool, error) {
+	var pal savePal
+
+	reader := &gvasReader{data: raw}
+	object, err := reader.readProperties()
+	if err != nil {
+		return pal, false, err
+	}
+	saveParameter, ok := object["SaveParameter"]
+	if !ok {
+		return pal, false, nil
+	}
+	params, err := saveParameter.structProperties()
+	if err != nil {
+		return pal, false, err
+	}
+
+	if prop, ok := params["IsPlayer"]; ok && prop.boolValue {
+		return pal, false, nil
+	}
+	owner := ""
+	if prop, ok := params["OwnerPlayerUId"]; ok {
+		owner, _ = prop.guid()
+	}
+	if owner == "" || owner == zeroGuid {
+		return pal, false, nil
+	}
+
+	if prop, ok := params["CharacterID"]; ok {
+		if pal.characterID, err = prop.string(); err != nil {
+			return pal, false, err
+		}
+	}
+	if prop, ok := params["Gender"]; ok {
+		gender, err := prop.string()
+		if err != nil {
+			return pal, false, err
+		}
+		switch {
+		case strings.HasSuffix(gender, "::Male"):
+			pal.gender = "m"
+		case strings.HasSuffix(gender, "::Female"):
+			pal.gender = "f"
+		}
+	}
+	if prop, ok := params["NickName"]; ok {
+		if pal.nickname, err = prop.string(); err != nil {
+			return pal, false, err
+		}
+	}
+	if prop, ok := params["PassiveSkillList"]; ok {
+		if pal.passives, err = prop.stringArray(); err != nil {
+			return pal, false, err
+		}
+	}
+
+	// Missing numbers are left at their defaults: level 1, rank 1 (no stars), talents 0
+	pal.level = 1
+	rank := 1
+	for name, target := range map[string]*int{
+		"Level":          &pal.level,
+		"Rank":           &rank,
+		"Talent_HP":      &pal.talentHP,
+		"Talent_Shot":    &pal.talentAttack,
+		"Talent_Defense": &pal.talentDefense,
+	} {
+		if prop, ok := params[name]; ok {
+			if *target, err = prop.int(); err != nil {
+				return pal, false, fmt.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+	pal.condensationRank = max(rank-1, 0)
+
+	return pal, true, nil
+}
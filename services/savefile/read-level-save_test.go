@@ -0,0 +1,220 @@
+package savefile
+
+import (
+	"palworld_tools/config"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixturePals are the pals owned in the fixtures, the player and the wild pal are left out
+var fixturePals = []savePal{
+	{
+		instanceID:       fixtureLamballID,
+		characterID:      "SheepBall",
+		gender:           "f",
+		nickname:         "Woolly",
+		level:            12,
+		talentHP:         50,
+		talentAttack:     60,
+		talentDefense:    70,
+		condensationRank: 2,
+		passives:         []string{"CraftSpeed_up2", "Deffence_up1"},
+	},
+	{
+		instanceID:  fixtureFoxparksID,
+		characterID: "BOSS_Kitsunebi",
+		gender:      "m",
+		nickname:    "Füchslein",
+		level:       30,
+		passives:    []string{"Rare", "Unknown_Passive"},
+	},
+}
+
+func TestGvasReaderReadsHeaderAndProperties(t *testing.T) {
+	reader := &gvasReader{data: levelGvas()}
+	if err := reader.skipHeader(); err != nil {
+		t.Fatalf("skipHeader: %v", err)
+	}
+	root, err := reader.readProperties()
+	if err != nil {
+		t.Fatalf("readProperties: %v", err)
+	}
+	if reader.remaining() != 0 {
+		t.Errorf("%d bytes left after the root properties", reader.remaining())
+	}
+
+	version, err := root["Version"].int()
+	if err != nil || version != 100 {
+		t.Errorf("Version = %d, %v, want 100", version, err)
+	}
+	if timestamp := root["Timestamp"]; timestamp == nil || timestamp.innerType != "DateTime" || len(timestamp.value) != 8 {
+		t.Errorf("Timestamp = %+v, want an 8 byte DateTime struct", timestamp)
+	}
+	world, err := root["worldSaveData"].structProperties()
+	if err != nil {
+		t.Fatalf("worldSaveData: %v", err)
+	}
+	if _, err := world["SaveVersion"].string(); err == nil {
+		t.Errorf("reading an IntProperty as a string should fail")
+	}
+
+	var characters int
+	err = world["CharacterSaveParameterMap"].structMap(func(key properties, value properties) error {
+		characters++
+		if _, err := key["InstanceId"].guid(); err != nil {
+			return err
+		}
+		_, err := value["RawData"].byteArray()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("structMap: %v", err)
+	}
+	if characters != 4 {
+		t.Errorf("got %d characters, want 4", characters)
+	}
+}
+
+func TestGvasReaderStrings(t *testing.T) {
+	for _, text := range []string{"", "SheepBall", "Füchslein"} {
+		w := &gvasWriter{}
+		w.fstring(text)
+		got, err := (&gvasReader{data: w.Bytes()}).fstring()
+		if err != nil || got != text {
+			t.Errorf("fstring = %q, %v, want %q", got, err, text)
+		}
+	}
+
+	if _, err := (&gvasReader{data: []byte{10, 0, 0, 0, 'a'}}).fstring(); err == nil {
+		t.Errorf("reading past the end should fail")
+	}
+}
+
+func TestReadLevelSave(t *testing.T) {
+	for _, name := range []string{"Level.sav", "Level-zlib.sav", "Level-cnk.sav"} {
+		pals, err := readLevelSave(readFixture(t, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(pals, fixturePals) {
+			t.Errorf("%s: got %+v, want %+v", name, pals, fixturePals)
+		}
+	}
+}
+
+func TestReadCharacterSkipsPlayersAndWildPals(t *testing.T) {
+	for name, saveParameter := range map[string][][]byte{
+		"player":      {boolProp("IsPlayer", true), guidProp("OwnerPlayerUId", fixturePlayerGuid)},
+		"wild pal":    {nameProp("CharacterID", "Penguin"), guidProp("OwnerPlayerUId", zeroGuid)},
+		"no owner":    {nameProp("CharacterID", "Penguin")},
+		"no settings": nil,
+	} {
+		raw := props(structProp("SaveParameter", "PalIndividualCharacterSaveParameter", props(saveParameter...)))
+		if name == "no settings" {
+			raw = props()
+		}
+		_, owned, err := readCharacter(raw)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if owned {
+			t.Errorf("%s: reported as owned", name)
+		}
+	}
+}
+
+func TestImportLevelSave(t *testing.T) {
+	service, err := datamanage.NewService(&config.Config{
+		DataDir:                t.TempDir(),
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if err := service.WritePaldex([]models.Pal{{Name: "Lamball"}, {Name: "Foxparks"}}); err != nil {
+		t.Fatalf("WritePaldex: %v", err)
+	}
+	passiveSkills := []models.PassiveSkill{{Name: "Artisan"}, {Name: "Hard Skin"}, {Name: "Lucky"}}
+	if err := service.WritePassiveSkills(passiveSkills); err != nil {
+		t.Fatalf("WritePassiveSkills: %v", err)
+	}
+
+	result, err := ImportLevelSave(service, datamanage.DefaultProfile, readFixture(t, "Level.sav"), datamanage.ImportModeMerge)
+	if err != nil {
+		t.Fatalf("ImportLevelSave: %v", err)
+	}
+	if result.Found != 2 || result.Added != 2 || len(result.Skipped) != 0 {
+		t.Errorf("got %+v, want 2 pals found and added", result)
+	}
+	if !reflect.DeepEqual(result.UnknownPassives, []string{"Unknown_Passive"}) {
+		t.Errorf("UnknownPassives = %v, want [Unknown_Passive]", result.UnknownPassives)
+	}
+
+	palStore, err := service.ReadStoredPals(datamanage.DefaultProfile)
+	if err != nil {
+		t.Fatalf("ReadStoredPals: %v", err)
+	}
+	stored := make(map[string]models.StoredPal)
+	for _, species := range palStore {
+		for _, pal := range species.StoredPals {
+			stored[species.Name] = pal
+		}
+	}
+
+	lamball := stored["Lamball"]
+	if lamball.ID != fixtureLamballID || lamball.Gender != "f" || lamball.Level != 12 || lamball.CondensationRank != 2 ||
+		lamball.Talents != (models.Talents{HP: 50, Attack: 60, Defense: 70}) ||
+		!reflect.DeepEqual(lamball.PassiveSkills, []string{"Artisan", "Hard Skin"}) {
+		t.Errorf("Lamball = %+v", lamball)
+	}
+	foxparks := stored["Foxparks"]
+	if foxparks.ID != fixtureFoxparksID || foxparks.Gender != "m" || foxparks.Nickname != "Füchslein" ||
+		!reflect.DeepEqual(foxparks.PassiveSkills, []string{"Lucky"}) {
+		t.Errorf("Foxparks = %+v", foxparks)
+	}
+
+	// Importing the same save again updates the pals instead of adding them twice
+	result, err = ImportLevelSave(service, datamanage.DefaultProfile, readFixture(t, "Level.sav"), datamanage.ImportModeMerge)
+	if err != nil {
+		t.Fatalf("ImportLevelSave again: %v", err)
+	}
+	if result.Added != 0 || result.Updated != 2 {
+		t.Errorf("got %+v on the second import, want 2 pals updated", result.ImportResult)
+	}
+}
+
+func TestImportLevelSaveSkipsInvalidPals(t *testing.T) {
+	service, err := datamanage.NewService(&config.Config{
+		DataDir:                t.TempDir(),
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+		MaxPalLevel:            20,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	if err := service.WritePaldex([]models.Pal{{Name: "Lamball"}, {Name: "Foxparks"}}); err != nil {
+		t.Fatalf("WritePaldex: %v", err)
+	}
+
+	// The Foxparks of the fixtures is level 30, above the configured cap
+	save := wrapSave(levelGvasWith(fixtureCharacters()...), "PlZ", saveTypeZlib, false)
+	result, err := ImportLevelSave(service, datamanage.DefaultProfile, save, datamanage.ImportModeMerge)
+	if err != nil {
+		t.Fatalf("ImportLevelSave: %v", err)
+	}
+	if result.Added != 1 || len(result.Skipped) != 1 || result.Skipped[0].Id != fixtureFoxparksID ||
+		!strings.Contains(result.Skipped[0].Reason, "level") {
+		t.Errorf("got %+v, want the Lamball added and the Foxparks skipped for its level", result)
+	}
+}
@@ -0,0 +1,231 @@
+package savefile
+
+import (
+	"palworld_tools/models"
+	"strings"
+	"unicode"
+)
+
+// speciesCodes maps the CharacterID of a pal in the save to its paldex name,
+// keyed in lower case. Codes that match the paldex name already are left out.
+var speciesCodes = map[string]string{
+	"sheepball":        "Lamball",
+	"pinkcat":          "Cattiva",
+	"chickenpal":       "Chikipi",
+	"carbunclo":        "Lifmunk",
+	"kitsunebi":        "Foxparks",
+	"blueplatypus":     "Fuack",
+	"eleccat":          "Sparkit",
+	"monkey":           "Tanzee",
+	"flamebambi":       "Rooby",
+	"penguin":          "Pengullet",
+	"captainpenguin":   "Penking",
+	"hedgehog":         "Jolthog",
+	"plantslime":       "Gumoss",
+	"cutefox":          "Vixy",
+	"ganesha":          "Teafant",
+	"negativekoala":    "Depresso",
+	"woolfox":          "Cremis",
+	"dreamdemon":       "Daedream",
+	"boar":             "Rushoar",
+	"nightfox":         "Nox",
+	"cutemole":         "Fuddler",
+	"bastet":           "Mau",
+	"flyingmanta":      "Celaray",
+	"garm":             "Direhowl",
+	"colorfulbird":     "Tocotoco",
+	"flowerrabbit":     "Flopie",
+	"cowpal":           "Mozzarina",
+	"sharkkid":         "Gobfin",
+	"windchimes":       "Hangyu",
+	"grasspanda":       "Mossanda",
+	"sweetssheep":      "Woolipop",
+	"berrygoat":        "Caprity",
+	"alpaca":           "Melpaca",
+	"deer":             "Eikthyrdeer",
+	"pinkrabbit":       "Ribbuny",
+	"baphomet":         "Incineram",
+	"cutebutterfly":    "Cinnamoth",
+	"flamebuffalo":     "Arsox",
+	"lazycatfish":      "Dumud",
+	"darkcrow":         "Cawgnito",
+	"lizardman":        "Leezpunk",
+	"werewolf":         "Loupmoon",
+	"robinhood":        "Robinquill",
+	"gorilla":          "Gorirat",
+	"soldierbee":       "Beegarde",
+	"queenbee":         "Elizabee",
+	"naughtycat":       "Grintale",
+	"mopbaby":          "Swee",
+	"mopking":          "Sweepa",
+	"weaseldragon":     "Chillet",
+	"kirin":            "Univolt",
+	"icefox":           "Foxcicle",
+	"firekirin":        "Pyrin",
+	"icedeer":          "Reindrix",
+	"thunderdog":       "Rayhound",
+	"amaterasuwolf":    "Kitsun",
+	"raijindaughter":   "Dazzi",
+	"mutant":           "Lunaris",
+	"flowerdinosaur":   "Dinossom",
+	"serpent":          "Surfent",
+	"ghostbeast":       "Maraith",
+	"drillgame":        "Digtoise",
+	"pinklizard":       "Lovander",
+	"lavagirl":         "Flambelle",
+	"birddragon":       "Vanwyrm",
+	"thunderbird":      "Beakon",
+	"redarmorbird":     "Ragnahawk",
+	"catmage":          "Katress",
+	"foxmage":          "Wixen",
+	"violetfairy":      "Vaelet",
+	"fairydragon":      "Elphidran",
+	"kelpie":           "Kelpsea",
+	"bluedragon":       "Azurobe",
+	"manticore":        "Blazehowl",
+	"lazydragon":       "Relaxaurus",
+	"sakurasaurus":     "Broncherry",
+	"volcanicmonster":  "Reptyro",
+	"kingalpaca":       "Kingpaca",
+	"grassmammoth":     "Mammorest",
+	"yeti":             "Wumpo",
+	"herculesbeetle":   "Warsect",
+	"fengyundeeper":    "Fenglope",
+	"catvampire":       "Felbat",
+	"kingbahamut":      "Blazamut",
+	"hadesbird":        "Helzephyr",
+	"darkscorpion":     "Menasting",
+	"umihebi":          "Jormuntide",
+	"elecpanda":        "Grizzbolt",
+	"lilyqueen":        "Lyleen",
+	"horus":            "Faleris",
+	"thunderdragonman": "Orserk",
+	"blackgriffon":     "Shadowbeak",
+	"saintcentaur":     "Paladius",
+	"blackcentaur":     "Necromus",
+	"icehorse":         "Frostallion",
+	"jetdragon":        "Jetragon",
+	"wizardowl":        "Hoocrates",
+	"negativeoctopus":  "Killamari",
+	"littlebriarrose":  "Bristla",
+	"hawkbird":         "Nitewing",
+	"eagle":            "Galeclaw",
+	"catbat":           "Tombat",
+	"ronin":            "Bushi",
+	"grassrabbitman":   "Verdash",
+	"whitemoth":        "Sibelyx",
+	"whitetiger":       "Cryolinx",
+	"flowerdoll":       "Petallia",
+	"skydragon":        "Quivern",
+	"blackmetaldragon": "Astegon",
+	"nightlady":        "Bellanoir",
+	"nightlady_dark":   "Bellanoir Libero",
+}
+
+// variantSuffixes maps the element suffix of a CharacterID to the paldex variant name
+var variantSuffixes = map[string]string{
+	"ice":      "Cryst",
+	"dark":     "Noct",
+	"electric": "Lux",
+	"ground":   "Terra",
+	"fire":     "Ignis",
+	"water":    "Aqua",
+	"grass":    "Botan",
+	"dragon":   "Ryu",
+}
+
+// passiveCodes maps the internal passive skill names in the save to their display
+// names, keyed in lower case. Codes that match the display name are left out.
+var passiveCodes = map[string]string{
+	"rare":                   "Lucky",
+	"craftspeed_up1":         "Serious",
+	"craftspeed_up2":         "Artisan",
+	"craftspeed_down1":       "Clumsy",
+	"craftspeed_down2":       "Slacker",
+	"pal_allattack_up1":      "Brave",
+	"pal_allattack_up2":      "Ferocious",
+	"pal_allattack_down1":    "Coward",
+	"pal_allattack_down2":    "Pacifist",
+	"deffence_up1":           "Hard Skin",
+	"deffence_up2":           "Burly Body",
+	"deffence_down1":         "Downtrodden",
+	"deffence_down2":         "Brittle",
+	"pal_sanity_down_1":      "Positive Thinker",
+	"pal_sanity_down_2":      "Workaholic",
+	"pal_sanity_up_1":        "Unstable",
+	"pal_sanity_up_2":        "Destructive",
+	"pal_fullstomach_down_1": "Dainty Eater",
+	"pal_fullstomach_down_2": "Diet Lover",
+	"pal_fullstomach_up_1":   "Glutton",
+	"pal_fullstomach_up_2":   "Bottomless Stomach",
+	"pal_corporateslave":     "Work Slave",
+	"pal_rude":               "Hooligan",
+	"pal_sadist":             "Sadist",
+	"pal_masochist":          "Masochist",
+	"pal_conceited":          "Conceited",
+	"noukin":                 "Musclehead",
+	"movespeed_up_1":         "Nimble",
+	"movespeed_up_2":         "Runner",
+	"movespeed_up_3":         "Swift",
+}
+
+// speciesName resolves a CharacterID to a paldex name. Alpha pals carry a "BOSS_"
+// prefix and element variants a suffix such as "_Ice".
+func speciesName(pals []models.Pal, characterID string) (string, bool) {
+	code := strings.ToLower(characterID)
+	code = strings.TrimPrefix(code, "boss_")
+
+	if pal := resolveSpecies(pals, code); pal != nil {
+		return pal.Name, true
+	}
+
+	base, suffix, found := strings.Cut(code, "_")
+	if !found {
+		return "", false
+	}
+	variant, ok := variantSuffixes[suffix]
+	if !ok {
+		return "", false
+	}
+	if pal := resolveSpecies(pals, base); pal != nil {
+		if variantPal := models.FindPal(pals, pal.Name+" "+variant); variantPal != nil {
+			return variantPal.Name, true
+		}
+	}
+	return "", false
+}
+
+func resolveSpecies(pals []models.Pal, code string) *models.Pal {
+	if name, ok := speciesCodes[code]; ok {
+		return models.FindPal(pals, name)
+	}
+	for i := range pals {
+		if normalizeCode(pals[i].Name) == normalizeCode(code) {
+			return &pals[i]
+		}
+	}
+	return nil
+}
+
+// passiveName resolves an internal passive skill name to its display name
+func passiveName(passiveSkills []models.PassiveSkill, code string) (string, bool) {
+	if name, ok := passiveCodes[strings.ToLower(code)]; ok {
+		code = name
+	}
+	for _, skill := range passiveSkills {
+		if normalizeCode(skill.Name) == normalizeCode(code) {
+			return skill.Name, true
+		}
+	}
+	return "", false
+}
+
+// normalizeCode lower cases text and drops everything but letters and digits
+func normalizeCode(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+}
@@ -0,0 +1,73 @@
+package savefile
+
+import (
+	"os"
+	"palworld_tools/config"
+	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpeciesNameResolvesShippedPaldex(t *testing.T) {
+	pals := shippedPaldex(t)
+
+	for code, name := range speciesCodes {
+		if models.FindPal(pals, name) == nil {
+			t.Errorf("code %s maps to %s, which isn't in the paldex", code, name)
+		}
+	}
+
+	cases := []struct {
+		characterID string
+		want        string
+	}{
+		{"SheepBall", "Lamball"},
+		{"BOSS_Kitsunebi", "Foxparks"},
+		{"Anubis", "Anubis"},
+		{"Suzaku_Water", "Suzaku Aqua"},
+		{"IceHorse_Dark", "Frostallion Noct"},
+		{"Ronin_Dark", "Bushi Noct"},
+		{"WhiteTiger_Ground", "Cryolinx Terra"},
+		{"KingBahamut_Dragon", "Blazamut Ryu"},
+		{"BOSS_NightLady_Dark", "Bellanoir Libero"},
+		{"NoSuchPal", ""},
+	}
+	for _, c := range cases {
+		got, ok := speciesName(pals, c.characterID)
+		if got != c.want || ok != (c.want != "") {
+			t.Errorf("speciesName(%s) = %q, %v, want %q", c.characterID, got, ok, c.want)
+		}
+	}
+}
+
+// shippedPaldex reads data/pals.json from a copy, as reading it may upgrade the file
+func shippedPaldex(t *testing.T) []models.Pal {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("..", "..", "data", "pals.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pals.json"), content, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	service, err := datamanage.NewService(&config.Config{
+		DataDir:                dir,
+		PalsFile:               "pals.json",
+		StoredPalsFile:         "stored_pals.json",
+		PassiveSkillsFile:      "passive_skills.json",
+		PassiveSkillCombosFile: "passive_skill_combos.json",
+		StoreBackend:           datamanage.StoreBackendJSON,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	pals, err := service.Paldex()
+	if err != nil {
+		t.Fatalf("Paldex: %v", err)
+	}
+	return pals
+}
@@ -1,11 +1,10 @@
 package scrapper
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
 	"regexp"
 	"sort"
 	"strconv"
@@ -23,19 +22,14 @@ func min(a, b int) int {
 	return b
 }
 
-func ScrapperPalInfo() error {
+func ScrapperPalInfo(service *datamanage.Service) error {
 	// URL of the Game8 Palworld Pals info page
 	url := "https://game8.co/games/Palworld/archives/439556"
 
-	// Read existing pals info data or create new slice if file doesn't exist
-	var pals []models.Pal
-	data, err := os.ReadFile("./data/pals.json")
-	if err == nil {
-		err = json.Unmarshal(data, &pals)
-		if err != nil {
-			fmt.Println("Error parsing existing pals.json:", err)
-			return err
-		}
+	// Read existing pals info data or create new slice if none is stored yet
+	pals, err := service.ReadPaldex()
+	if err != nil {
+		return err
 	}
 
 	// Fetch the HTML doc
@@ -61,6 +55,7 @@ func ScrapperPalInfo() error {
 			if existingPal == nil {
 				// Create new Pal entry
 				imageUrl := getImageFromPalworldWiki(name)
+				wikiInfo := getInfoFromPalworldWiki(name)
 				suitabilities := getSuitabilityCol(row)
 				children := getChildrenCol(row)
 
@@ -68,15 +63,34 @@ func ScrapperPalInfo() error {
 					Id:          id,
 					Name:        name,
 					ImageUrl:    imageUrl,
+					CombiRank:   wikiInfo.CombiRank,
+					Rarity:      wikiInfo.Rarity,
+					Egg:         wikiInfo.Egg,
 					Suitability: suitabilities,
 					Children:    children,
 				}
 
 				pals = append(pals, pal)
-			} else if existingPal.ImageUrl == "" {
-				// Update existing Pal with image URL if it's missing
-				fmt.Printf("Updating image for existing Pal: %s\n", name)
-				existingPal.ImageUrl = getImageFromPalworldWiki(name)
+			} else {
+				if existingPal.ImageUrl == "" {
+					// Update existing Pal with image URL if it's missing
+					fmt.Printf("Updating image for existing Pal: %s\n", name)
+					existingPal.ImageUrl = getImageFromPalworldWiki(name)
+				}
+				if existingPal.CombiRank == 0 || existingPal.Rarity == 0 || existingPal.Egg == "" {
+					// Fill in breeding data that is missing, keeping the values already stored
+					fmt.Printf("Updating breeding data for existing Pal: %s\n", name)
+					wikiInfo := getInfoFromPalworldWiki(name)
+					if existingPal.CombiRank == 0 {
+						existingPal.CombiRank = wikiInfo.CombiRank
+					}
+					if existingPal.Rarity == 0 {
+						existingPal.Rarity = wikiInfo.Rarity
+					}
+					if existingPal.Egg == "" {
+						existingPal.Egg = wikiInfo.Egg
+					}
+				}
 			}
 
 		})
@@ -117,19 +131,13 @@ func ScrapperPalInfo() error {
 
 	})
 
-	// Convert the slice to JSON
-	jsonData, err := json.MarshalIndent(pals, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// // Write the JSON data to a file
-	err = os.WriteFile("./data/pals.json", jsonData, 0644)
+	// Save the pals through the configured store
+	err = service.WritePaldex(pals)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("\nPal data saved to pals.json result is", len(pals))
+	fmt.Println("\nPal data saved result is", len(pals))
 
 	return nil
 }
@@ -319,3 +327,57 @@ func getImageFromPalworldWiki(palName string) string {
 	fmt.Printf("Found image URL for %s: %s\n", palName, imageUrl)
 	return imageUrl
 }
+
+// palWikiInfo holds the breeding values read from a Pal's infobox on the Palworld wiki
+type palWikiInfo struct {
+	CombiRank int
+	Rarity    int
+	Egg       string
+}
+
+// getInfoFromPalworldWiki fetches the breeding power (CombiRank), rarity and egg from the Palworld wiki page for a specific Pal
+// Values that can't be found are left empty
+func getInfoFromPalworldWiki(palName string) palWikiInfo {
+	var info palWikiInfo
+	wikiURL := fmt.Sprintf("https://palworld.wiki.gg/wiki/%s", strings.ReplaceAll(palName, " ", "_"))
+
+	fmt.Printf("Fetching breeding data from: %s\n", wikiURL)
+
+	// Add a small delay to be respectful to the server
+	time.Sleep(1 * time.Second)
+
+	doc, err := fetchDataToDoc(wikiURL)
+	if err != nil {
+		fmt.Printf("Error fetching wiki page for %s: %v\n", palName, err)
+		return info
+	}
+
+	numberRe := regexp.MustCompile(`\d+`)
+	info.CombiRank, _ = strconv.Atoi(numberRe.FindString(getInfoboxValue(doc, "Breeding Power", "Combi Rank")))
+	info.Rarity, _ = strconv.Atoi(numberRe.FindString(getInfoboxValue(doc, "Rarity")))
+
+	// Egg names look like "Large Damp Egg"
+	eggRe := regexp.MustCompile(`(?:(?:Huge|Large)\s+)?(?:\w+\s+)?Egg`)
+	info.Egg = eggRe.FindString(getInfoboxValue(doc, "Egg"))
+
+	fmt.Printf("Found breeding data for %s: %+v\n", palName, info)
+	return info
+}
+
+// getInfoboxValue returns the infobox text that follows the first matching label
+func getInfoboxValue(doc *goquery.Document, labels ...string) string {
+	value := ""
+	doc.Find(".infobox tr, .portable-infobox .pi-data").Each(func(i int, row *goquery.Selection) {
+		if value != "" {
+			return
+		}
+		text := strings.TrimSpace(row.Text())
+		for _, label := range labels {
+			if strings.HasPrefix(text, label) {
+				value = strings.TrimSpace(strings.TrimPrefix(text, label))
+				return
+			}
+		}
+	})
+	return value
+}
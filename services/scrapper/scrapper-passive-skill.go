@@ -1,12 +1,11 @@
 package scrapper
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"palworld_tools/models"
+	"palworld_tools/services/datamanage"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,19 +13,14 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-func ScrapperPassiveSkill() error {
+func ScrapperPassiveSkill(service *datamanage.Service) error {
 	// URL of the Game8 Palworld Passive Skills page
 	url := "https://game8.co/games/Palworld/archives/439667"
 
-	// Read existing passive skills data or create new slice if file doesn't exist
-	var passiveSkills []models.PassiveSkill
-	data, err := os.ReadFile("passive_skills.json")
-	if err == nil {
-		err = json.Unmarshal(data, &passiveSkills)
-		if err != nil {
-			fmt.Println("Error parsing existing passive skill data.json:", err)
-			return err
-		}
+	// Read existing passive skills data or create new slice if none is stored yet
+	passiveSkills, err := service.ReadPassiveSkills()
+	if err != nil {
+		return err
 	}
 
 	// Fetch the HTML document
@@ -93,19 +87,13 @@ func ScrapperPassiveSkill() error {
 		return passiveSkills[i].Tier < passiveSkills[j].Tier
 	})
 
-	// Convert the slice to JSON
-	jsonData, err := json.MarshalIndent(passiveSkills, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Write the JSON data to a file
-	err = os.WriteFile("./data/passive_skills.json", jsonData, 0644)
+	// Save the passive skills through the configured store
+	err = service.WritePassiveSkills(passiveSkills)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("Passive skills data saved to passive_skills.json result is", len(passiveSkills))
+	fmt.Println("Passive skills data saved result is", len(passiveSkills))
 
 	return nil
 
@@ -120,12 +108,7 @@ func isPassiveSkillExists(passiveSkills []models.PassiveSkill, pk string) bool {
 	return false
 }
 
-type ComboPassiveSkill struct {
-	Name   string
-	Skills []string
-}
-
-func BestComboPassiveSkill() error {
+func BestComboPassiveSkill(service *datamanage.Service) error {
 	// URL of the Game8 Palworld Best Combo Passive Skills page
 	url := "https://game8.co/games/Palworld/archives/440414"
 
@@ -136,7 +119,7 @@ func BestComboPassiveSkill() error {
 	}
 
 	// Read existing combo passive skills data or create new slice if file doesn't exist
-	var comboPks []ComboPassiveSkill
+	var comboPks []models.PassiveSkillCombo
 
 	// Fetch the HTML document
 	resp, err := http.Get(url)
@@ -188,22 +171,16 @@ func BestComboPassiveSkill() error {
 				})
 			}
 		})
-		comboPks = append(comboPks, ComboPassiveSkill{Name: comboName, Skills: comboObj})
-	}
-
-	// Convert the slice to JSON
-	jsonData, err := json.MarshalIndent(comboPks, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+		comboPks = append(comboPks, models.PassiveSkillCombo{Name: comboName, Skills: comboObj})
 	}
 
-	// Write the JSON data to a file
-	err = os.WriteFile("./data/passive_skill_combos.json", jsonData, 0644)
+	// Save the combos through the configured store
+	err = service.WritePassiveSkillCombos(comboPks)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("Combo passive skills data saved to passive_skill_combos.json result is", len(comboPks))
+	fmt.Println("Combo passive skills data saved result is", len(comboPks))
 
 	return nil
 }